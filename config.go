@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"gopkg.in/yaml.v3"
+)
+
+// config is what can be set in config.yml. Anything given on the command
+// line takes precedence over it.
+type config struct {
+	Saver    string        `yaml:"saver"`
+	Playlist []string      `yaml:"playlist"`
+	Rotate   time.Duration `yaml:"rotate"`
+	FPS      int           `yaml:"fps"`
+	Exit     string        `yaml:"exit"`
+	Chord    string        `yaml:"chord"`
+	Duration time.Duration `yaml:"duration"`
+	// Passphrase is the bcrypt hash of the passphrase that ends --lock.
+	Passphrase string `yaml:"passphrase"`
+	// Color is used for the --color input of every saver that has one.
+	Color string `yaml:"color"`
+	// Savers holds default inputs by saver name, then input name.
+	Savers map[string]map[string]string `yaml:"savers"`
+	// Presets are named sets of inputs for a saver, by saver name and then
+	// preset name. They are run as saver:preset.
+	Presets map[string]map[string]map[string]string `yaml:"presets"`
+}
+
+// configDir is where config.yml lives, following the XDG base directory
+// spec.
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh-screensaver")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh-screensaver")
+}
+
+// loadConfig reads config.yml. Not having one is the same as having an empty
+// one.
+func loadConfig() (*config, error) {
+	cfg := &config{}
+	dir := configDir()
+	if dir == "" {
+		return cfg, nil
+	}
+	path := filepath.Join(dir, "config.yml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// apply fills in opts from the config wherever the matching flag wasn't
+// given, and makes any saver:preset names used in opts runnable by adding
+// them to opts.Savers. opts.Savers must already be set.
+func (c *config) apply(flags *pflag.FlagSet, opts *shared.ScreensaverOpts) error {
+	unset := func(name string) bool {
+		return flags.Lookup(name) != nil && !flags.Changed(name)
+	}
+	if unset("saver") && c.Saver != "" {
+		opts.Screensaver = c.Saver
+	}
+	// A headless run draws a single saver, so it isn't rotated even when
+	// rotating is the default.
+	if unset("rotate") && c.Rotate != 0 && !opts.Headless {
+		opts.Rotate = c.Rotate
+	}
+	// The playlist is only of use to a run that moves between savers; any
+	// other run ignores it rather than failing over a setting it didn't ask
+	// for.
+	if unset("playlist") && len(c.Playlist) > 0 && (opts.Rotate > 0 || opts.Controls) {
+		opts.Playlist = c.Playlist
+	}
+	if unset("fps") && c.FPS != 0 {
+		opts.FPS = c.FPS
+	}
+	if unset("exit") && c.Exit != "" {
+		opts.Exit = c.Exit
+	}
+	if unset("chord") && c.Chord != "" {
+		opts.ExitChord = c.Chord
+	}
+	if unset("duration") && c.Duration != 0 {
+		opts.Duration = c.Duration
+	}
+	opts.ColorMode = c.Color
+	opts.LockHash = c.Passphrase
+
+	opts.InputDefaults = map[string]map[string]string{}
+	for name, inputs := range c.Savers {
+		if _, ok := opts.Savers[name]; !ok {
+			return fmt.Errorf("config file sets inputs for '%s', which is not a screensaver", name)
+		}
+		opts.InputDefaults[name] = inputs
+	}
+
+	names := append([]string{opts.Screensaver}, opts.Playlist...)
+	names = append(names, strings.Split(opts.Mosaic, ",")...)
+	names = append(names, opts.Layers...)
+	for _, name := range names {
+		if !strings.Contains(name, ":") {
+			continue
+		}
+		if err := c.addPreset(name, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPreset registers saver:preset as a saver of its own whose input
+// defaults are the saver's defaults overridden by the preset.
+func (c *config) addPreset(name string, opts *shared.ScreensaverOpts) error {
+	i := strings.Index(name, ":")
+	base, preset := name[:i], name[i+1:]
+	rs, ok := opts.Savers[base]
+	if !ok {
+		return fmt.Errorf("no such screensaver '%s'; run gh screensaver -l to see choices", base)
+	}
+	inputs, ok := c.Presets[base][preset]
+	if !ok {
+		return fmt.Errorf("no preset '%s' for %s in %s", preset, base, filepath.Join(configDir(), "config.yml"))
+	}
+
+	merged := map[string]string{}
+	for k, v := range opts.InputDefaults[base] {
+		merged[k] = v
+	}
+	for k, v := range inputs {
+		merged[k] = v
+	}
+	opts.Savers[name] = rs
+	opts.InputDefaults[name] = merged
+	return nil
+}
+
+// presetNames lists every preset as saver:preset.
+func (c *config) presetNames() []string {
+	names := []string{}
+	for saver, presets := range c.Presets {
+		for preset := range presets {
+			names = append(names, saver+":"+preset)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+const testConfig = `
+saver: marquee
+fps: 20
+savers:
+  marquee:
+    message: team
+    font: small
+presets:
+  marquee:
+    standup:
+      message: standup!
+`
+
+// loadTestConfig loads yml as though it were the user's config.yml.
+func loadTestConfig(t *testing.T, yml string) *config {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "gh-screensaver"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gh-screensaver", "config.yml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old, had := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv("XDG_CONFIG_HOME", old)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	})
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestConfigApply(t *testing.T) {
+	cfg := loadTestConfig(t, testConfig)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts := shared.ScreensaverOpts{Savers: registeredSavers()}
+	flags.StringVarP(&opts.Screensaver, "saver", "s", "", "")
+	flags.IntVar(&opts.FPS, "fps", 0, "")
+	if err := flags.Parse([]string{"-s", "marquee:standup"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.apply(flags, &opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Screensaver != "marquee:standup" {
+		t.Errorf("--saver was overridden by the config file: got %s", opts.Screensaver)
+	}
+	if opts.FPS != 20 {
+		t.Errorf("got fps %d, want 20", opts.FPS)
+	}
+	if _, ok := opts.Savers["marquee:standup"]; !ok {
+		t.Fatal("preset was not added to the savers")
+	}
+	want := map[string]string{"message": "standup!", "font": "small"}
+	if got := opts.InputDefaults["marquee:standup"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got preset inputs %v, want %v", got, want)
+	}
+
+	opts.Screensaver = "marquee:retro"
+	if err := cfg.apply(pflag.NewFlagSet("test", pflag.ContinueOnError), &opts); err == nil {
+		t.Error("expected an error for a preset that doesn't exist")
+	}
+}
+
+func TestConfigPlaylistWithoutRotate(t *testing.T) {
+	cfg := loadTestConfig(t, "playlist: [starfield, pipes]\n")
+
+	for _, tt := range []struct {
+		args []string
+		want []string
+	}{
+		{nil, nil},
+		{[]string{"--rotate", "1m"}, []string{"starfield", "pipes"}},
+		{[]string{"--controls"}, []string{"starfield", "pipes"}},
+	} {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		opts := shared.ScreensaverOpts{Savers: registeredSavers()}
+		flags.StringSliceVar(&opts.Playlist, "playlist", nil, "")
+		flags.DurationVar(&opts.Rotate, "rotate", 0, "")
+		flags.BoolVar(&opts.Controls, "controls", false, "")
+		if err := flags.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.apply(flags, &opts); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(opts.Playlist, tt.want) {
+			t.Errorf("%v: got playlist %v, want %v", tt.args, opts.Playlist, tt.want)
+		}
+	}
+}
+
+func TestConfigRotateHeadless(t *testing.T) {
+	cfg := loadTestConfig(t, "rotate: 5m\n")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts := shared.ScreensaverOpts{Savers: registeredSavers(), Headless: true}
+	flags.DurationVar(&opts.Rotate, "rotate", 0, "")
+	if err := cfg.apply(flags, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Rotate != 0 {
+		t.Errorf("a headless run was given rotate %s from the config file", opts.Rotate)
+	}
+}
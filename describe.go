@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vilmibm/gh-screensaver/screensaver"
+)
+
+func describeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe <saver>",
+		Short: "Show what a screensaver is and the inputs it takes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			savers := registeredSavers()
+			rs, ok := savers[args[0]]
+			if !ok {
+				return fmt.Errorf("no such screensaver '%s'; run gh screensaver -l to see choices", args[0])
+			}
+			return screensaver.Describe(cmd.OutOrStdout(), args[0], rs)
+		},
+	}
+}
+
+const rootLong = `
+By default, runs a random screensaver.
+
+When selecting a specific screensaver with -s, some of them support
+configuration options that can be passed after --. For example:
+
+gh screensaver -smarquee -- --message="hello world" --font="script"
+
+With --rotate, --mosaic or --layers, options go to every screensaver that
+runs. Prefix an option with a screensaver's name to give it to just that
+one, or pass --lenient to let screensavers ignore options they don't have:
+
+gh screensaver --rotate 5m -- --marquee.message="hi" --life.color=off
+
+Run gh screensaver describe <saver> to see just one screensaver's options.`
+
+// rootHelp builds the root command's long help from every registered saver,
+// so that it can't disagree with what the savers actually accept.
+func rootHelp() string {
+	var b strings.Builder
+	b.WriteString(rootLong)
+	b.WriteString("\n\nScreensavers:\n")
+	savers := registeredSavers()
+	for _, name := range screensaver.Names(savers) {
+		b.WriteString("\n")
+		if err := screensaver.Describe(&b, name, savers[name]); err != nil {
+			fmt.Fprintf(&b, "%s\n  %s\n", name, err)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"github.com/vilmibm/gh-screensaver/screensaver"
+)
+
+func exportCmd() *cobra.Command {
+	opts := shared.ScreensaverOpts{}
+	var output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Render a screensaver to an animated GIF",
+		Long: `
+Renders a screensaver without a terminal and saves it as an animated GIF.
+Inputs for the screensaver can be passed after --, just like when running it.
+
+gh screensaver export --saver pipes --frames 300 --size 100x30 -o pipes.gif`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.SaverArgs = args
+			opts.Savers = registeredSavers()
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := cfg.apply(cmd.Flags(), &opts); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("seed") {
+				opts.Seed = time.Now().UTC().UnixNano()
+			}
+
+			return screensaver.ExportGIF(cmd.Context(), opts, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Screensaver, "saver", "s", "", "Screensaver to export")
+	cmd.Flags().IntVar(&opts.Frames, "frames", 100, "Number of frames to render")
+	cmd.Flags().StringVar(&opts.Size, "size", "80x24", "Screen size to render, in cells")
+	cmd.Flags().IntVar(&opts.FPS, "fps", 0, "Frames per second, overriding the screensaver's own rate")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 0, "Seed for random choices (default random)")
+	cmd.Flags().StringVar(&opts.CrashReport, "crash-report", "", "Write the details to `file` if the screensaver crashes")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "GIF `file` to write")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
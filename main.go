@@ -1,197 +1,122 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"math/rand"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
-	"github.com/vilmibm/gh-screensaver/savers"
 	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"github.com/vilmibm/gh-screensaver/screensaver"
 )
 
-func runScreensaver(opts shared.ScreensaverOpts) error {
-	style := tcell.StyleDefault
-	opts.Style = style
-
-	saverInit, ok := opts.Savers[opts.Screensaver]
-	if !ok {
-		return fmt.Errorf("no such screensaver '%s'; run gh screensaver -l to see choices", opts.Screensaver)
-	}
-
-	screen, err := tcell.NewScreen()
-	if err != nil {
-		return err
-	}
-	if err = screen.Init(); err != nil {
-		return err
-	}
-	screen.SetStyle(style)
-
-	opts.Screen = screen
-
-	saver, err := saverInit(opts)
-	if err != nil {
-		return err
-	}
-
-	// TODO ignore unused parameters
-	providedInputs := map[string]string{}
-	fs := pflag.FlagSet{}
-	for inputName, input := range saver.Inputs() {
-		fs.String(inputName, input.Default, input.Description)
-	}
-	err = fs.Parse(opts.SaverArgs)
-	if err != nil {
-		if !strings.Contains(err.Error(), "unknown flag") {
-			return fmt.Errorf("could not parse input args: %w", err)
-		}
-	}
-	for inputName := range saver.Inputs() {
-		providedValue, _ := fs.GetString(inputName)
-		providedInputs[inputName] = providedValue
-	}
-
-	err = saver.SetInputs(providedInputs)
-	if err != nil {
-		return err
-	}
-
-	quit := make(chan struct{})
-	go func() {
-		for {
-			ev := screen.PollEvent()
-			switch ev.(type) {
-			case *tcell.EventKey:
-				close(quit)
-				return
-			case *tcell.EventResize:
-				screen.Sync()
-			}
-		}
-	}()
-
-	var saverErr error
-loop:
-	for {
-		select {
-		case <-quit:
-			break loop
-		case <-time.After(time.Millisecond * 100):
-		}
-
-		saver.Clear()
-		if err := saver.Update(); err != nil {
-			saverErr = err
-			break loop
-		}
-		screen.Show()
-	}
-
-	screen.Fini()
-
-	return saverErr
-}
-
 func rootCmd() *cobra.Command {
 	opts := shared.ScreensaverOpts{}
 	cmd := &cobra.Command{
-		Use:   "screensaver",
-		Short: "Watch a terminal saver animation",
-		Long: `
-By default, runs a random screensaver.
-
-When selecting a specific screensaver with -s, some of them support 
-configuration options that can be passed after --. For example:
-
-gh screensaver -smarquee -- --message="hello world" --font="script"
-
-marquee
-  --message="custom message"
-  --font="script"
-
-  Fonts: banner, big, block, bubble, digital, lean, mini, mnemonic,
-         script, shadow, slant, small, smscript, smshadow, smslant,
-         standard, term
-
-fireworks
-  --color can either be "full" of "off"
-
-starfield
-  --density is the maximum number of stars to draw (default 250)
-  --speed is the speed to fly through space (default 4)
-
-pipes
-  --color can either be "full" of "off"
-
-life
-  --seed is the starting state. (default random)
-
-  Seeds: gilder, R, dragon, pulsar, gun, noise`,
+		Use:          "screensaver",
+		Short:        "Watch a terminal saver animation",
+		Long:         rootLong,
+		Args:         cobra.ArbitraryArgs,
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.SaverArgs = args
+			opts.Savers = registeredSavers()
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := cfg.apply(cmd.Flags(), &opts); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("seed") {
+				opts.Seed = time.Now().UTC().UnixNano()
+			}
 			if opts.Repository == "" {
 				repo, _ := resolveRepository()
 				// Not erroring here; if a saver requires to know the repository it
 				// will have to error itself if opts.Repository is ""
 				opts.Repository = repo
 			}
-			opts.Savers = map[string]shared.SaverCreator{
-				"marquee":   savers.NewMarqueeSaver,
-				"fireworks": savers.NewFireworksSaver,
-				"pipes":     savers.NewPipesSaver,
-				"starfield": savers.NewStarfieldSaver,
-				"pollock":   savers.NewPollockSaver,
-				"life":      savers.NewLifeSaver,
-				// TODO aquarium
-				// TODO noise
-				// TODO issues/pr float by?
-			}
-			if opts.Screensaver == "" {
-				opts.Screensaver = pickRandom(opts.Savers)
-			}
 			if opts.List {
-				for _, k := range saverKeys(opts.Savers) {
+				for _, k := range screensaver.Names(opts.Savers) {
+					if !strings.Contains(k, ":") {
+						fmt.Println(k)
+					}
+				}
+				for _, k := range cfg.presetNames() {
 					fmt.Println(k)
 				}
 				return nil
 			}
 
-			return runScreensaver(opts)
+			opts.Out = cmd.OutOrStdout()
+			return screensaver.Run(cmd.Context(), opts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&opts.Repository, "repo", "R", "", "Run in the context of a repo. Currently unused.")
 	cmd.Flags().StringVarP(&opts.Screensaver, "saver", "s", "", "Screensaver to play")
 	cmd.Flags().BoolVarP(&opts.List, "list", "l", false, "List available screensavers and exit")
+	cmd.Flags().IntVar(&opts.FPS, "fps", 0, "Frames per second, overriding the screensaver's own rate")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 0, "Seed for random choices, to reproduce a run (default random)")
+	cmd.Flags().BoolVar(&opts.Headless, "headless", false, "Render without a terminal and print the result")
+	cmd.Flags().IntVar(&opts.Frames, "frames", 100, "Number of frames to render with --headless")
+	cmd.Flags().StringVar(&opts.Size, "size", "80x24", "Screen size to render with --headless")
+	cmd.Flags().StringVar(&opts.Format, "format", "text", "Output format for --headless: text or ansi")
+	cmd.Flags().BoolVar(&opts.AllFrames, "all-frames", false, "Print every frame with --headless, not just the last")
+	cmd.Flags().StringVar(&opts.Record, "record", "", "Record the session to an asciicast v2 `file` for asciinema")
+	cmd.Flags().DurationVar(&opts.Rotate, "rotate", 0, "Switch to the next screensaver after this long, e.g. 5m")
+	cmd.Flags().StringSliceVar(&opts.Playlist, "playlist", nil, "Comma separated screensavers to rotate through (default all)")
+	cmd.Flags().BoolVar(&opts.Shuffle, "shuffle", false, "Rotate through screensavers in a random order")
+	cmd.Flags().StringVar(&opts.Transition, "transition", "dissolve", "How to switch between screensavers: "+strings.Join(screensaver.Transitions, ", "))
+	cmd.Flags().DurationVar(&opts.TransitionDuration, "transition-duration", time.Second, "How long switching between screensavers takes")
+	cmd.Flags().StringVar(&opts.Mosaic, "mosaic", "", "Tile several screensavers at once: `COLSxROWS` of random ones, or a comma separated list")
+	cmd.Flags().StringSliceVar(&opts.Layers, "layers", nil, "Comma separated screensavers to draw one over the other, bottom first")
+	cmd.Flags().BoolVar(&opts.Controls, "controls", false, "Use keys to pause, skip and speed up screensavers; press ? to see them")
+	cmd.Flags().StringVar(&opts.Exit, "exit", "key", "What ends the screensaver: "+strings.Join(screensaver.ExitPolicies, ", "))
+	cmd.Flags().StringVar(&opts.ExitChord, "chord", screensaver.DefaultChord, "Key chord that ends the screensaver with --exit chord")
+	cmd.Flags().DurationVar(&opts.Duration, "duration", 0, "End the screensaver after this long, e.g. 30m")
+	cmd.Flags().BoolVar(&opts.Lock, "lock", false, "Keep running until the passphrase from the config file is typed")
+	cmd.Flags().StringVar(&opts.CrashReport, "crash-report", "", "Write the details to `file` if a screensaver crashes")
+	cmd.Flags().BoolVar(&opts.Debug, "debug", false, "Report screensavers drawing outside the screen when done")
+	cmd.Flags().BoolVar(&opts.Lenient, "lenient", false, "Ignore screensaver inputs that the screensaver doesn't take")
+
+	cmd.AddCommand(exportCmd())
+	cmd.AddCommand(describeCmd())
+	cmd.AddCommand(passphraseCmd())
+	cmd.AddCommand(previewCmd())
+
+	// Describing every saver means creating one of each, so only do it when
+	// help is actually asked for.
+	defaultHelp := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		if c == cmd {
+			c.Long = rootHelp()
+		}
+		defaultHelp(c, args)
+	})
 
 	return cmd
 }
 
-func saverKeys(savers map[string]shared.SaverCreator) []string {
-	keys := []string{}
-	for k := range savers {
-		keys = append(keys, k)
-	}
-
-	return keys
-}
-
-func pickRandom(savers map[string]shared.SaverCreator) string {
-	rand.Seed(time.Now().UTC().UnixNano())
-	keys := saverKeys(savers)
-	ix := rand.Intn(len(keys))
-	return keys[ix]
+// registeredSavers returns the built in savers and any plugins that were
+// found.
+func registeredSavers() map[string]shared.RegisteredSaver {
+	registry := screensaver.Savers()
+	addPlugins(registry)
+	return registry
 }
 
 func main() {
 	rc := rootCmd()
 
-	if err := rc.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := rc.ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
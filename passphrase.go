@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+func passphraseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "passphrase",
+		Short: "Make a passphrase hash for --lock",
+		Long: `
+Asks for a passphrase and prints its bcrypt hash, to go in config.yml as
+passphrase: for gh screensaver --lock. When not run in a terminal, the
+passphrase is read from the first line of stdin instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, err := readPassphrase()
+			if err != nil {
+				return err
+			}
+			if passphrase == "" {
+				return errors.New("the passphrase can't be empty")
+			}
+			hash, err := bcrypt.GenerateFromPassword([]byte(passphrase), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(hash))
+			return nil
+		},
+	}
+}
+
+// readPassphrase asks for the passphrase twice without echoing it, or reads a
+// line from stdin if it isn't a terminal.
+func readPassphrase() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("could not read a passphrase from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	ask := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		p, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(p), err
+	}
+	first, err := ask("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	again, err := ask("Again: ")
+	if err != nil {
+		return "", err
+	}
+	if first != again {
+		return "", errors.New("the passphrases don't match")
+	}
+	return first, nil
+}
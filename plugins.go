@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/vilmibm/gh-screensaver/savers"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// pluginPrefix starts the name of every plugin executable. What follows it
+// is the saver's name.
+const pluginPrefix = "gh-screensaver-"
+
+// findPlugins looks for plugin executables in the plugins directory next to
+// the config file and then on PATH. When two have the same name, the first
+// one found wins.
+func findPlugins() map[string]string {
+	dirs := []string{}
+	if dir := configDir(); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, "plugins"))
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+
+	found := map[string]string{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasPrefix(name, pluginPrefix) || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				if !strings.HasSuffix(name, ".exe") {
+					continue
+				}
+				name = strings.TrimSuffix(name, ".exe")
+			} else if info, err := e.Info(); err != nil || info.Mode()&0111 == 0 {
+				continue
+			}
+			name = strings.TrimPrefix(name, pluginPrefix)
+			if _, ok := found[name]; !ok && name != "" {
+				found[name] = filepath.Join(dir, e.Name())
+			}
+		}
+	}
+	return found
+}
+
+// addPlugins registers every plugin that doesn't share a name with a saver
+// already in registry.
+func addPlugins(registry map[string]shared.RegisteredSaver) {
+	for name, path := range findPlugins() {
+		if _, ok := registry[name]; ok {
+			continue
+		}
+		registry[name] = shared.RegisteredSaver{
+			Create: savers.NewPluginSaver(path),
+			Metadata: shared.SaverMetadata{
+				Description: "Plugin at " + path,
+			},
+		}
+	}
+}
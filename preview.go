@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"github.com/vilmibm/gh-screensaver/screensaver"
+)
+
+func previewCmd() *cobra.Command {
+	opts := shared.ScreensaverOpts{}
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Show every screensaver at once",
+		Long: `
+Runs every screensaver side by side, each under its name, to help pick one.
+Presets from the config file are included. Inputs can be passed after --,
+and each screensaver ignores those it doesn't take:
+
+gh screensaver preview -- --color=off`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.SaverArgs = args
+			opts.Savers = registeredSavers()
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			names := []string{}
+			for _, name := range screensaver.Names(opts.Savers) {
+				if !strings.Contains(name, ":") {
+					names = append(names, name)
+				}
+			}
+			opts.Mosaic = strings.Join(append(names, cfg.presetNames()...), ",")
+			opts.Labels = true
+			opts.Lenient = true
+			if err := cfg.apply(cmd.Flags(), &opts); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("seed") {
+				opts.Seed = time.Now().UTC().UnixNano()
+			}
+
+			opts.Out = cmd.OutOrStdout()
+			return screensaver.Run(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.FPS, "fps", 0, "Frames per second, overriding the screensavers' own rates")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", 0, "Seed for random choices, to reproduce a run (default random)")
+
+	return cmd
+}
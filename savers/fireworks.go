@@ -11,12 +11,25 @@ import (
 
 // Not for MVP but would be cool to have "rare" fireworks that occur way less frequently.
 
+var FireworksMetadata = shared.SaverMetadata{
+	Description: "Watch a fireworks display.",
+	Author:      "nate smith",
+	// Fireworks launch at least 5 columns in and burst at least 8 rows up.
+	MinWidth:  12,
+	MinHeight: 12,
+}
+
 type FireworksSaver struct {
-	screen    tcell.Screen
+	canvas    *shared.Canvas
 	style     tcell.Style
+	interval  time.Duration
+	rand      *rand.Rand
+	stepper   *shared.Stepper
 	color     bool
-	inputs    map[string]string
 	fireworks []*firework
+	// buttons is what the mouse last had held, so that a click is told
+	// apart from the motion of a drag.
+	buttons tcell.ButtonMask
 }
 
 func NewFireworksSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
@@ -29,55 +42,86 @@ func NewFireworksSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error)
 }
 
 func (fs *FireworksSaver) Clear() {
-	fs.screen.Clear()
+	fs.canvas.Clear()
+}
+
+func (fs *FireworksSaver) Interval() time.Duration {
+	return fs.interval
 }
 
 func (fs *FireworksSaver) Initialize(opts shared.ScreensaverOpts) error {
-	fs.screen = opts.Screen
+	fs.canvas = opts.Canvas
 	fs.style = opts.Style
-
-	rand.Seed(time.Now().UTC().UnixNano())
+	fs.rand = opts.Rand
+	fs.interval = 70 * time.Millisecond
+	fs.stepper = &shared.Stepper{Every: 70 * time.Millisecond}
 
 	return nil
 }
 
+// Resize is a no-op; fireworks look at the screen size as they launch.
+func (fs *FireworksSaver) Resize(width, height int) {}
+
 func (fs *FireworksSaver) Inputs() map[string]shared.SaverInput {
 	// TODO eventually support truecolor
 	return map[string]shared.SaverInput{
-		"color": {
-			Default:     "full",
-			Description: "whether to use full color or monochrome. Values: full, off",
-		},
+		"color": colorModeInput,
 	}
 }
 
-func (fs *FireworksSaver) SetInputs(inputs map[string]string) error {
-	fs.inputs = inputs
-	if inputs["color"] == "full" {
-		fs.color = true
+func (fs *FireworksSaver) SetInputs(inputs shared.InputValues) error {
+	fs.color = inputs.String("color") == "full"
+	return nil
+}
+
+// HandleEvent launches a firework that bursts wherever the screen is
+// clicked. Dragging with the button held doesn't launch any more.
+func (fs *FireworksSaver) HandleEvent(ev tcell.Event) bool {
+	mev, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return false
+	}
+	held := fs.buttons
+	fs.buttons = mev.Buttons()
+	if mev.Buttons()&tcell.Button1 == 0 {
+		return false
 	}
+	if held != tcell.ButtonNone {
+		return true
+	}
+	x, y := mev.Position()
+	f := newFirework(fs.rand, fs.canvas, fs.style)
+	f.x, f.height = x, y
+	fs.fireworks = append(fs.fireworks, f)
+	return true
+}
+
+func (fs *FireworksSaver) Update(delta time.Duration) error {
+	for i := fs.stepper.Steps(delta); i > 0; i-- {
+		fs.step()
+	}
+
+	for _, f := range fs.fireworks {
+		f.Draw(fs.color)
+	}
+
 	return nil
 }
 
-func (fs *FireworksSaver) Update() error {
+func (fs *FireworksSaver) step() {
 	next := []*firework{}
 	for _, f := range fs.fireworks {
 		f.Update()
 		if !f.Done() {
 			next = append(next, f)
-		} else {
-			continue
 		}
-		f.Draw(fs.color)
 	}
 	fs.fireworks = next
 
 	// TODO tweak as needed
-	if rand.Intn(10) < 1 {
-		fs.fireworks = append(fs.fireworks, newFirework(fs.screen, fs.style))
+	if fs.rand.Intn(10) < 1 {
+		fs.fireworks = append(fs.fireworks, newFirework(fs.rand, fs.canvas, fs.style))
 	}
-
-	return nil
 }
 
 type sprite struct {
@@ -111,7 +155,7 @@ type firework struct {
 	x             int
 	y             int
 	height        int
-	screen        tcell.Screen
+	canvas        *shared.Canvas
 	style         tcell.Style
 	exploding     bool
 	done          bool
@@ -344,17 +388,17 @@ var lightColors = []tcell.Color{
 	tcell.ColorLightYellow,
 }
 
-func newFirework(screen tcell.Screen, style tcell.Style) *firework {
-	width, height := screen.Size()
-	colorIx := rand.Intn(len(colors))
-	trailIx := rand.Intn(len(trails))
-	explosionIx := rand.Intn(len(explosions))
+func newFirework(r *rand.Rand, canvas *shared.Canvas, style tcell.Style) *firework {
+	width, height := canvas.Size()
+	colorIx := r.Intn(len(colors))
+	trailIx := r.Intn(len(trails))
+	explosionIx := r.Intn(len(explosions))
 	f := &firework{
-		screen:        screen,
+		canvas:        canvas,
 		style:         style,
-		x:             rand.Intn(width-5) + 5,
+		x:             r.Intn(width-5) + 5,
 		y:             height,
-		height:        rand.Intn(height - 8),
+		height:        r.Intn(height - 8),
 		TrailSprite:   trails[trailIx](),
 		ExplodeSprite: explosions[explosionIx](),
 		Color1:        colors[colorIx],
@@ -364,6 +408,15 @@ func newFirework(screen tcell.Screen, style tcell.Style) *firework {
 }
 
 func (f *firework) Update() {
+	if f.exploding {
+		f.ExplodeSprite.Advance()
+		if f.ExplodeSprite.Done() {
+			f.done = true
+		}
+		return
+	}
+
+	f.TrailSprite.Advance()
 	if f.y == f.height {
 		f.exploding = true
 	} else {
@@ -371,19 +424,12 @@ func (f *firework) Update() {
 	}
 }
 
-// TODO affordance for setting animation interval from within screensaver
-
 func (f *firework) Done() bool {
 	return f.done
 }
 
 func (f *firework) Draw(useColor bool) {
 	if f.exploding {
-		if f.ExplodeSprite.Done() {
-			f.done = true
-			return
-		}
-
 		color := f.Color1
 		colorChoice := f.ExplodeSprite.Frame % 2
 		if colorChoice == 1 {
@@ -391,16 +437,21 @@ func (f *firework) Draw(useColor bool) {
 		}
 
 		lines := strings.Split(f.ExplodeSprite.CurrentFrame(), "\n")
+		s := f.style
+		if useColor {
+			s = f.style.Foreground(color)
+		}
 		for ix, line := range lines {
-			s := f.style
-			if useColor {
-				s = f.style.Foreground(color)
+			// Only the burst itself is drawn, so that whatever is behind it
+			// shows through the gaps. Some lines of the sprites are indented
+			// with tabs, which are gaps too.
+			for dx, r := range line {
+				if r != ' ' && r != '\t' {
+					f.canvas.DrawString(f.x-2+dx, f.y+ix-2, s, string(r))
+				}
 			}
-			drawStr(f.screen, f.x-2, f.y+ix-2, s, line)
 		}
 
-		f.ExplodeSprite.Advance()
-
 		return
 	}
 
@@ -413,6 +464,5 @@ func (f *firework) Draw(useColor bool) {
 	if useColor {
 		s = f.style.Foreground(color)
 	}
-	drawStr(f.screen, f.x, f.y, s, f.TrailSprite.CurrentFrame())
-	f.TrailSprite.Advance()
+	f.canvas.DrawString(f.x, f.y, s, f.TrailSprite.CurrentFrame())
 }
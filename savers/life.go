@@ -2,8 +2,8 @@ package savers
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
-	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -12,6 +12,16 @@ import (
 
 var seeds = []string{"dragon", "gun", "noise", "r", "pulsar", "glider"}
 
+// seedSizes is the smallest board each starting pattern fits on.
+var seedSizes = map[string][2]int{
+	"dragon": {26, 30},
+	"gun":    {50, 42},
+	"noise":  {10, 10},
+	"r":      {15, 15},
+	"pulsar": {43, 39},
+	"glider": {10, 10},
+}
+
 var aliveColorsBlue = []tcell.Color{
 	tcell.ColorDeepSkyBlue,
 	tcell.ColorBlue,
@@ -32,16 +42,35 @@ var aliveColorsWhite = []tcell.Color{
 	tcell.ColorGold,
 }
 
+var LifeMetadata = shared.SaverMetadata{
+	Description: "Conway's game of life, starting from a famous pattern or noise.",
+	Author:      "@meiji163",
+	MinWidth:    10,
+	MinHeight:   10,
+}
+
 type LifeSaver struct {
-	screen tcell.Screen
-	style  tcell.Style
+	canvas   *shared.Canvas
+	style    tcell.Style
+	interval time.Duration
 
 	width  int
 	height int
 
+	stepper    *shared.Stepper
+	rand       *rand.Rand
 	useColor   bool
 	colors     []tcell.Color
 	aliveCells [][]int
+	// seed is the starting pattern. It is stamped on the board once the board
+	// is big enough for it, which started records.
+	seed    string
+	started bool
+
+	// dragging is set while the mouse button is held, and lastToggled is the
+	// cell it last toggled, so that dragging across a cell flips it once.
+	dragging    bool
+	lastToggled coord
 }
 
 func NewLifeSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
@@ -50,24 +79,82 @@ func NewLifeSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
 		return nil, err
 	}
 
-	lf.aliveCells = make([][]int, lf.width)
-	for i := range lf.aliveCells {
-		lf.aliveCells[i] = make([]int, lf.height)
-	}
+	lf.aliveCells = newGrid(lf.width, lf.height)
 
 	return lf, nil
 }
 
+func newGrid(width, height int) [][]int {
+	grid := make([][]int, width)
+	for i := range grid {
+		grid[i] = make([]int, height)
+	}
+	return grid
+}
+
+// Resize keeps whatever part of the current board still fits on screen.
+func (lf *LifeSaver) Resize(width, height int) {
+	grid := newGrid(width, height)
+	for i := 0; i < width && i < lf.width; i++ {
+		copy(grid[i], lf.aliveCells[i])
+	}
+	lf.aliveCells = grid
+	lf.width, lf.height = width, height
+	if !lf.started && lf.fits(lf.seed) {
+		_ = lf.initState(lf.seed)
+	}
+}
+
+// MinSize is the size of the starting pattern.
+func (lf *LifeSaver) MinSize() (int, int) {
+	size := seedSizes[lf.seed]
+	return size[0], size[1]
+}
+
+func (lf *LifeSaver) fits(seed string) bool {
+	size := seedSizes[seed]
+	return lf.width >= size[0] && lf.height >= size[1]
+}
+
+// HandleEvent toggles the cells that are clicked or dragged over.
+func (lf *LifeSaver) HandleEvent(ev tcell.Event) bool {
+	mev, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return false
+	}
+	if mev.Buttons()&tcell.Button1 == 0 {
+		lf.dragging = false
+		return false
+	}
+	x, y := mev.Position()
+	c := coord{x, y}
+	if x < 0 || x >= lf.width || y < 0 || y >= lf.height || (lf.dragging && c == lf.lastToggled) {
+		return true
+	}
+	if lf.aliveCells[x][y] == 0 {
+		lf.aliveCells[x][y] = 1
+	} else {
+		lf.aliveCells[x][y] = 0
+	}
+	lf.dragging, lf.lastToggled = true, c
+	return true
+}
+
 func (lf *LifeSaver) Clear() {
-	lf.screen.Clear()
+	lf.canvas.Clear()
+}
+
+func (lf *LifeSaver) Interval() time.Duration {
+	return lf.interval
 }
 
 func (lf *LifeSaver) Initialize(opts shared.ScreensaverOpts) error {
-	lf.screen = opts.Screen
+	lf.canvas = opts.Canvas
 	lf.style = opts.Style
-	lf.width, lf.height = lf.screen.Size()
-
-	rand.Seed(time.Now().UTC().UnixNano())
+	lf.rand = opts.Rand
+	lf.interval = 60 * time.Millisecond
+	lf.stepper = &shared.Stepper{Every: 60 * time.Millisecond}
+	lf.width, lf.height = lf.canvas.Size()
 
 	return nil
 }
@@ -76,54 +163,38 @@ func (lf *LifeSaver) Inputs() map[string]shared.SaverInput {
 	return map[string]shared.SaverInput{
 		"seed": {
 			Default:     "rand",
-			Description: "seed state. Values: dragon, gun, R, pulsar, noise",
-		},
-		"color": {
-			Default:     "full",
-			Description: "whether to use full color or monochrome. Values: full, off",
+			Description: "Starting pattern",
+			Type:        shared.InputEnum,
+			Values:      append([]string{"rand"}, seeds...),
 		},
+		"color": colorModeInput,
 	}
 }
 
-func (lf *LifeSaver) SetInputs(inputs map[string]string) error {
-	lf.useColor = inputs["color"] == "full"
-	seed := strings.ToLower(inputs["seed"])
+func (lf *LifeSaver) SetInputs(inputs shared.InputValues) error {
+	lf.useColor = inputs.String("color") == "full"
+	seed := inputs.String("seed")
 	if seed == "rand" {
-		idx := rand.Intn(len(seeds))
-		seed = seeds[idx]
-	}
-
-	// default to noise if terminal is too small
-	defaultSeed := "noise"
-	switch seed {
-	case "gun":
-		if lf.width < 50 || lf.height < 42 {
-			seed = defaultSeed
-		}
-	case "pulsar":
-		if lf.width < 40 || lf.height < 40 {
-			seed = defaultSeed
-		}
-	case "dragon":
-		if lf.width < 26 || lf.height < 30 {
-			seed = defaultSeed
-		}
-	case "r":
-		if lf.width < 15 || lf.height < 15 {
-			seed = defaultSeed
-		}
-	case "glider":
-		if lf.width < 10 || lf.height < 10 {
-			seed = defaultSeed
+		// Only patterns that fit are picked at random; noise always does.
+		fitting := []string{}
+		for _, s := range seeds {
+			if lf.fits(s) || s == "noise" {
+				fitting = append(fitting, s)
+			}
 		}
-	default:
-		seed = defaultSeed
+		seed = fitting[lf.rand.Intn(len(fitting))]
 	}
-	err := lf.initState(seed)
-	if err != nil {
-		return err
+	if _, ok := seedSizes[seed]; !ok {
+		return fmt.Errorf("unknown seed '%s'", seed)
 	}
-	return nil
+	lf.seed = seed
+
+	// A pattern that doesn't fit yet is put down when the terminal grows, via
+	// Resize; until then the framework doesn't run the saver.
+	if !lf.fits(seed) {
+		return nil
+	}
+	return lf.initState(seed)
 }
 
 func (lf *LifeSaver) initState(seed string) error {
@@ -186,7 +257,7 @@ func (lf *LifeSaver) initState(seed string) error {
 		//random noise seed
 		for i := 0; i < lf.width; i++ {
 			for j := 0; j < lf.height; j++ {
-				if rand.Intn(10) < 2 {
+				if lf.rand.Intn(10) < 2 {
 					lf.aliveCells[i][j] = 1
 				} else {
 					lf.aliveCells[i][j] = 0
@@ -197,7 +268,7 @@ func (lf *LifeSaver) initState(seed string) error {
 	case "glider":
 		//glider fleet
 		for k := 2; k+3 < lf.width; k += 15 {
-			h := rand.Intn(lf.height)
+			h := lf.rand.Intn(lf.height)
 			for i := 0; i < 3; i++ {
 				for j := 0; j < 3; j++ {
 					lf.aliveCells[k+i][(j+h+lf.height)%lf.height] = glider[i][j]
@@ -208,6 +279,7 @@ func (lf *LifeSaver) initState(seed string) error {
 	default:
 		return errors.New("Error initiliazing seed")
 	}
+	lf.started = true
 
 	return nil
 }
@@ -216,11 +288,42 @@ func (lf *LifeSaver) isOnGrid(x, y int) bool {
 	return x >= 0 && y >= 0 && x < lf.width && y < lf.height
 }
 
-func (lf *LifeSaver) Update() error {
+func (lf *LifeSaver) Update(delta time.Duration) error {
+	for i := lf.stepper.Steps(delta); i > 0; i-- {
+		lf.step()
+	}
+
+	for i := 0; i < lf.width; i++ {
+		for j := 0; j < lf.height; j++ {
+			switch lf.aliveCells[i][j] {
+			case 1:
+				if lf.useColor {
+					lf.canvas.DrawString(i, j, lf.style.Foreground(lf.colors[0]), "*")
+				} else {
+					lf.canvas.DrawString(i, j, lf.style, "*")
+				}
+			case 2:
+				if lf.useColor {
+					lf.canvas.DrawString(i, j, lf.style.Foreground(lf.colors[1]), "#")
+				} else {
+					lf.canvas.DrawString(i, j, lf.style, "*")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// step advances the board one generation. Live cells are left as 1 if they
+// have two neighbours or were just born, and 2 if they have three, so that
+// they can be drawn differently.
+func (lf *LifeSaver) step() {
 	for i := 0; i < lf.width; i++ {
 		for j := 0; j < lf.height; j++ {
 			if lf.aliveCells[i][j] > 0 {
 				// cell is alive
+				lf.aliveCells[i][j] = 1
 				for k := 0; k < 8; k++ {
 					ni := (i + nbrX[k] + lf.width) % lf.width
 					nj := (j + nbrY[k] + lf.height) % lf.height
@@ -244,30 +347,16 @@ func (lf *LifeSaver) Update() error {
 	// next generation
 	for i := 0; i < lf.width; i++ {
 		for j := 0; j < lf.height; j++ {
-			n := lf.aliveCells[i][j]
-			switch {
+			switch n := lf.aliveCells[i][j]; {
 			case n == -3 || n == 3:
-				if lf.useColor {
-					drawStr(lf.screen, i, j, lf.style.Foreground(lf.colors[0]), "*")
-				} else {
-					drawStr(lf.screen, i, j, lf.style, "*")
-				}
 				lf.aliveCells[i][j] = 1
 			case n == 4:
-				if lf.useColor {
-					drawStr(lf.screen, i, j, lf.style.Foreground(lf.colors[1]), "#")
-				} else {
-					drawStr(lf.screen, i, j, lf.style, "*")
-				}
-				lf.aliveCells[i][j] = 1
+				lf.aliveCells[i][j] = 2
 			default:
 				lf.aliveCells[i][j] = 0
-				drawStr(lf.screen, i, j, lf.style, " ")
 			}
 		}
 	}
-
-	return nil
 }
 
 var nbrX = []int{1, -1, 0, 1, -1, 0, 1, -1}
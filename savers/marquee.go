@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,28 +17,36 @@ import (
 //go:embed fonts/*
 var fonts embed.FS
 
-// TODO will likely share this
-func drawStr(s tcell.Screen, x, y int, style tcell.Style, str string) {
-	for _, c := range str {
-		var comb []rune
-		w := runewidth.RuneWidth(c)
-		if w == 0 {
-			comb = []rune{c}
-			c = ' '
-			w = 1
-		}
-		s.SetContent(x, y, c, comb, style)
-		x += w
+// fontNames lists the embedded fonts, without their .flf extension.
+func fontNames() []string {
+	entries, _ := fonts.ReadDir("fonts")
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".flf"))
 	}
+	sort.Strings(names)
+	return names
+}
+
+var MarqueeMetadata = shared.SaverMetadata{
+	Description: "Scroll a message across the screen in a FIGlet font.",
+	Author:      "nate smith",
+	// The tallest embedded fonts are eight rows.
+	MinWidth:  20,
+	MinHeight: 10,
 }
 
 type MarqueeSaver struct {
-	screen tcell.Screen
-	style  tcell.Style
-	x      int
-	y      int
-	banner string
-	inputs map[string]string
+	canvas   *shared.Canvas
+	style    tcell.Style
+	interval time.Duration
+	rand     *rand.Rand
+	x        float64
+	y        int
+	speed    float64
+	banner   string
+	message  string
+	font     *figletlib.Font
 }
 
 func NewMarqueeSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
@@ -45,56 +54,101 @@ func NewMarqueeSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
 	if err := bs.Initialize(opts); err != nil {
 		return nil, err
 	}
-	width, _ := bs.screen.Size()
-	bs.x = width
+	width, _ := bs.canvas.Size()
+	bs.x = float64(width)
 	return bs, nil
 }
 
 func (bs *MarqueeSaver) Clear() {
-	bs.screen.Clear()
+	bs.canvas.Clear()
+}
+
+func (bs *MarqueeSaver) Interval() time.Duration {
+	return bs.interval
 }
 
 func (bs *MarqueeSaver) Inputs() map[string]shared.SaverInput {
 	return map[string]shared.SaverInput{
 		"font": {
 			Default:     "slant",
-			Description: "Font file to use",
+			Description: "Font to use",
+			Type:        shared.InputEnum,
+			Values:      fontNames(),
 		},
 		"message": {
 			Default:     "text is cool",
 			Description: "Message to display",
 		},
+		"speed": {
+			Default:     "10",
+			Description: "How many columns per second the message scrolls",
+			Type:        shared.InputFloat,
+			Min:         0,
+			Max:         1000,
+		},
 	}
 }
 
-func (bs *MarqueeSaver) SetInputs(inputs map[string]string) error {
-	bs.inputs = inputs
-	data, err := fonts.ReadFile("fonts/" + bs.inputs["font"] + ".flf")
+func (bs *MarqueeSaver) SetInputs(inputs shared.InputValues) error {
+	bs.message = inputs.String("message")
+	bs.speed = inputs.Float("speed")
+
+	data, err := fonts.ReadFile("fonts/" + inputs.String("font") + ".flf")
 	if err != nil {
-		return fmt.Errorf("no such font: %s: %w", bs.inputs["font"], err)
+		return fmt.Errorf("could not read font: %w", err)
 	}
 
 	f, err := figletlib.ReadFontFromBytes(data)
 	if err != nil {
 		return err
 	}
-	width, _ := bs.screen.Size()
-	bs.banner = figletlib.SprintMsg(bs.inputs["message"], f, width, f.Settings(), "left")
+	bs.font = f
+	width, _ := bs.canvas.Size()
+	bs.render(width)
 	return nil
 }
 
+// render lays the message out in the chosen font, wrapping at width.
+func (bs *MarqueeSaver) render(width int) {
+	bs.banner = figletlib.SprintMsg(bs.message, bs.font, width, bs.font.Settings(), "left")
+}
+
+func (bs *MarqueeSaver) Resize(width, height int) {
+	if bs.font != nil {
+		bs.render(width)
+	}
+	if bs.x > float64(width) {
+		bs.x = float64(width)
+	}
+	lines := strings.Split(bs.banner, "\n")
+	if bs.y+len(lines) > height {
+		bs.y = 0
+	}
+}
+
+// MinSize leaves room for the message as laid out in its font, which is
+// taller than the metadata allows for with some font files or a message that
+// wraps.
+func (bs *MarqueeSaver) MinSize() (int, int) {
+	height := len(strings.Split(bs.banner, "\n")) + 1
+	if height < MarqueeMetadata.MinHeight {
+		height = MarqueeMetadata.MinHeight
+	}
+	return MarqueeMetadata.MinWidth, height
+}
+
 func (bs *MarqueeSaver) Initialize(opts shared.ScreensaverOpts) error {
-	bs.screen = opts.Screen
+	bs.canvas = opts.Canvas
 	bs.style = opts.Style
-
-	rand.Seed(time.Now().UTC().UnixNano())
+	bs.rand = opts.Rand
+	bs.interval = shared.DefaultInterval
 
 	return nil
 }
 
-func (bs *MarqueeSaver) Update() error {
-	width, height := bs.screen.Size()
-	bs.x--
+func (bs *MarqueeSaver) Update(delta time.Duration) error {
+	width, height := bs.canvas.Size()
+	bs.x -= bs.speed * delta.Seconds()
 
 	lines := strings.Split(bs.banner, "\n")
 	if len(lines) == 0 {
@@ -108,13 +162,25 @@ func (bs *MarqueeSaver) Update() error {
 		}
 	}
 
-	if bs.x+maxWidth < 0 {
-		bs.x = width
-		bs.y = rand.Intn(height - len(lines))
+	if int(bs.x)+maxWidth < 0 {
+		bs.x = float64(width)
+		bs.y = bs.rand.Intn(height - len(lines))
 	}
 
+	// Only the banner's letters are drawn, not the spaces around them, so
+	// that whatever is behind it shows through.
 	for ix, line := range lines {
-		drawStr(bs.screen, bs.x, bs.y+ix, bs.style, line)
+		x := int(bs.x)
+		for _, r := range line {
+			w := runewidth.RuneWidth(r)
+			if w == 0 {
+				w = 1
+			}
+			if r != ' ' {
+				bs.canvas.DrawString(x, bs.y+ix, bs.style, string(r))
+			}
+			x += w
+		}
 	}
 
 	return nil
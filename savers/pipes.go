@@ -8,12 +8,21 @@ import (
 	"github.com/vilmibm/gh-screensaver/savers/shared"
 )
 
+var PipesMetadata = shared.SaverMetadata{
+	Description: "2d pipes draw across the screen.",
+	Author:      "nate smith",
+	MinWidth:    1,
+	MinHeight:   1,
+}
+
 type PipesSaver struct {
-	screen tcell.Screen
-	style  tcell.Style
-	color  bool
-	pipes  []*pipe
-	inputs map[string]string
+	canvas   *shared.Canvas
+	style    tcell.Style
+	interval time.Duration
+	rand     *rand.Rand
+	stepper  *shared.Stepper
+	color    bool
+	pipes    []*pipe
 }
 
 func NewPipesSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
@@ -26,32 +35,37 @@ func NewPipesSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
 }
 
 func (ps *PipesSaver) Clear() {
-	ps.screen.Clear()
+	ps.canvas.Clear()
+}
+
+func (ps *PipesSaver) Interval() time.Duration {
+	return ps.interval
 }
 
 func (ps *PipesSaver) Initialize(opts shared.ScreensaverOpts) error {
-	ps.screen = opts.Screen
+	ps.canvas = opts.Canvas
 	ps.style = opts.Style
-
-	rand.Seed(time.Now().UTC().UnixNano())
+	ps.rand = opts.Rand
+	ps.interval = shared.DefaultInterval
+	ps.stepper = &shared.Stepper{Every: 100 * time.Millisecond}
 
 	return nil
 }
 
+func (ps *PipesSaver) Resize(width, height int) {
+	for _, p := range ps.pipes {
+		p.width, p.height = width, height
+	}
+}
+
 func (ps *PipesSaver) Inputs() map[string]shared.SaverInput {
 	return map[string]shared.SaverInput{
-		"color": {
-			Default:     "full",
-			Description: "whether to use full color or monochrome. Values: full, off",
-		},
+		"color": colorModeInput,
 	}
 }
 
-func (ps *PipesSaver) SetInputs(inputs map[string]string) error {
-	ps.inputs = inputs
-	if inputs["color"] == "full" {
-		ps.color = true
-	}
+func (ps *PipesSaver) SetInputs(inputs shared.InputValues) error {
+	ps.color = inputs.String("color") == "full"
 	return nil
 }
 
@@ -81,25 +95,25 @@ type pipe struct {
 	height int
 }
 
-func newPipe(width, height int) *pipe {
+func newPipe(r *rand.Rand, width, height int) *pipe {
 	p := &pipe{
-		color:  randColor(),
+		color:  randColor(r),
 		width:  width,
 		height: height,
 	}
 	x := 0
 	y := 0
-	switch rand.Intn(4) {
+	switch r.Intn(4) {
 	case 0: // top
-		x = rand.Intn(width)
+		x = r.Intn(width)
 	case 1: // right
 		x = width
-		y = rand.Intn(height)
+		y = r.Intn(height)
 	case 2: // bottom
 		y = height
-		x = rand.Intn(width)
+		x = r.Intn(width)
 	case 3: // left
-		y = rand.Intn(height)
+		y = r.Intn(height)
 	}
 
 	p.coords = []coord{{x, y}}
@@ -137,12 +151,12 @@ func turn(pd dir, d dir) dir {
 	return pd
 }
 
-func (p *pipe) Next() {
+func (p *pipe) Next(r *rand.Rand) {
 	last := p.coords[len(p.coords)-1]
 	// 80% continue in current dir
 	// 10% turn right
 	// 10% turn left
-	score := rand.Intn(10)
+	score := r.Intn(10)
 
 	// continue
 	if score == 8 {
@@ -175,23 +189,45 @@ func (p *pipe) AddCoord(c coord) {
 	p.coords = append(p.coords, c)
 }
 
-func randColor() tcell.Color {
-	r := rand.Int31n(255)
-	g := rand.Int31n(255)
-	b := rand.Int31n(255)
+func randColor(rnd *rand.Rand) tcell.Color {
+	r := rnd.Int31n(255)
+	g := rnd.Int31n(255)
+	b := rnd.Int31n(255)
 
 	return tcell.NewRGBColor(r, g, b)
 }
 
-func (ps *PipesSaver) Update() error {
-	width, height := ps.screen.Size()
-	if rand.Intn(10) < 1 {
+func (ps *PipesSaver) Update(delta time.Duration) error {
+	for i := ps.stepper.Steps(delta); i > 0; i-- {
+		ps.step()
+	}
+
+	for _, p := range ps.pipes {
+		for _, c := range p.coords {
+			s := ps.style
+			if ps.color {
+				s = s.Foreground(p.color)
+			}
+			ps.canvas.DrawString(c.x, c.y, s, "#")
+		}
+	}
+
+	// TODO the OG pipes clears itself at some interval. I think it will take far
+	// more time for us to fill up a screen, so initially I think i'll just let
+	// it fill up.
+	return nil
+}
+
+func (ps *PipesSaver) step() {
+	width, height := ps.canvas.Size()
+	if ps.rand.Intn(10) < 1 {
 		var pipe *pipe
 		for pipe == nil {
-			pipe = newPipe(width, height)
+			pipe = newPipe(ps.rand, width, height)
 			for _, p := range ps.pipes {
 				if p.coords[0].x == pipe.coords[0].x && p.coords[0].y == pipe.coords[0].y {
 					pipe = nil
+					break
 				} else {
 					break
 				}
@@ -202,18 +238,6 @@ func (ps *PipesSaver) Update() error {
 	}
 
 	for _, p := range ps.pipes {
-		p.Next()
-		for _, c := range p.coords {
-			s := ps.style
-			if ps.color {
-				s = s.Foreground(p.color)
-			}
-			drawStr(ps.screen, c.x, c.y, s, "#")
-		}
+		p.Next(ps.rand)
 	}
-
-	// TODO the OG pipes clears itself at some interval. I think it will take far
-	// more time for us to fill up a screen, so initially I think i'll just let
-	// it fill up.
-	return nil
 }
@@ -0,0 +1,402 @@
+package savers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// A plugin is an executable that draws a saver. It talks to the host over
+// stdin and stdout, one JSON object per line, each with a "type".
+//
+// The host sends:
+//
+//	{"type":"hello","seed":42}
+//	{"type":"resize","width":80,"height":24}
+//	{"type":"inputs","inputs":{"message":"hi","speed":10}}
+//	{"type":"tick","delta_ms":100}
+//	{"type":"key","key":"Rune[a]"}
+//	{"type":"mouse","x":3,"y":4,"buttons":1}
+//
+// and the plugin answers hello and tick, and nothing else:
+//
+//	{"type":"hello","interval_ms":100,"inputs":{"speed":{"default":"10","description":"...","type":"float","min":0,"max":100}}}
+//	{"type":"frame","clear":false,"cells":[{"x":1,"y":2,"ch":"*","fg":"red","bg":"","bold":true}]}
+//
+// A frame lists only the cells that changed; a cell with an empty "ch" is
+// erased, and "clear" erases everything first. Either answer may instead be
+// {"type":"error","message":"..."}, which stops the saver. When stdin closes
+// the plugin should exit.
+
+// pluginTimeout is how long a plugin has to answer before it is given up on.
+const pluginTimeout = 5 * time.Second
+
+// The messages the host sends. Each has only the fields of its type, and
+// none of them are omitted when zero, since a seed, delta or position of 0
+// means something.
+type (
+	helloMessage struct {
+		Type string `json:"type"`
+		Seed int64  `json:"seed"`
+	}
+	inputsMessage struct {
+		Type   string                 `json:"type"`
+		Inputs map[string]interface{} `json:"inputs"`
+	}
+	resizeMessage struct {
+		Type   string `json:"type"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	}
+	tickMessage struct {
+		Type    string `json:"type"`
+		DeltaMs int64  `json:"delta_ms"`
+	}
+	keyMessage struct {
+		Type string `json:"type"`
+		Key  string `json:"key"`
+	}
+	mouseMessage struct {
+		Type    string `json:"type"`
+		X       int    `json:"x"`
+		Y       int    `json:"y"`
+		Buttons int    `json:"buttons"`
+	}
+)
+
+// pluginFrame is the plugin's answer to tick.
+type pluginFrame struct {
+	Type    string       `json:"type"`
+	Clear   bool         `json:"clear"`
+	Cells   []pluginCell `json:"cells"`
+	Message string       `json:"message"`
+}
+
+// pluginHello is the plugin's answer to hello.
+type pluginHello struct {
+	Type       string                 `json:"type"`
+	IntervalMs int64                  `json:"interval_ms"`
+	Inputs     map[string]pluginInput `json:"inputs"`
+	Message    string                 `json:"message"`
+}
+
+type pluginInput struct {
+	Default     string   `json:"default"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"`
+	Min         float64  `json:"min"`
+	Max         float64  `json:"max"`
+	Values      []string `json:"values"`
+}
+
+type pluginCell struct {
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Ch        string `json:"ch"`
+	Fg        string `json:"fg,omitempty"`
+	Bg        string `json:"bg,omitempty"`
+	Bold      bool   `json:"bold,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+	Reverse   bool   `json:"reverse,omitempty"`
+	Dim       bool   `json:"dim,omitempty"`
+}
+
+// lockedBuffer collects a plugin's stderr, which is written to while the
+// plugin runs and read when something goes wrong.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+var inputTypes = map[string]shared.InputType{
+	"":         shared.InputString,
+	"string":   shared.InputString,
+	"int":      shared.InputInt,
+	"float":    shared.InputFloat,
+	"bool":     shared.InputBool,
+	"enum":     shared.InputEnum,
+	"color":    shared.InputColor,
+	"duration": shared.InputDuration,
+	"path":     shared.InputPath,
+}
+
+type PluginSaver struct {
+	path     string
+	canvas   *shared.Canvas
+	style    tcell.Style
+	interval time.Duration
+	inputs   map[string]shared.SaverInput
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	quit   chan struct{}
+	stderr lockedBuffer
+	closed bool
+
+	// cells is everything the plugin has drawn and not erased. The framework
+	// clears the screen before every frame, so it is all redrawn each time.
+	cells map[coord]pluginCell
+}
+
+// NewPluginSaver returns a creator for the plugin executable at path.
+func NewPluginSaver(path string) shared.SaverCreator {
+	return func(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+		ps := &PluginSaver{path: path}
+		if err := ps.Initialize(opts); err != nil {
+			return nil, err
+		}
+		return ps, nil
+	}
+}
+
+func (ps *PluginSaver) Initialize(opts shared.ScreensaverOpts) error {
+	ps.canvas = opts.Canvas
+	ps.style = opts.Style
+	ps.interval = shared.DefaultInterval
+	ps.cells = map[coord]pluginCell{}
+
+	ps.cmd = exec.Command(ps.path)
+	ps.cmd.Stderr = &ps.stderr
+	stdin, err := ps.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := ps.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := ps.cmd.Start(); err != nil {
+		return fmt.Errorf("could not start plugin %s: %w", ps.path, err)
+	}
+	ps.stdin = stdin
+
+	ps.lines = make(chan []byte)
+	ps.quit = make(chan struct{})
+	go func() {
+		defer close(ps.lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, 16*1024*1024)
+		for scanner.Scan() {
+			select {
+			case ps.lines <- append([]byte{}, scanner.Bytes()...):
+			case <-ps.quit:
+				return
+			}
+		}
+	}()
+
+	var hello pluginHello
+	if err := ps.request("hello", helloMessage{Type: "hello", Seed: opts.Seed}, &hello); err != nil {
+		ps.Close()
+		return err
+	}
+	if hello.Type == "error" {
+		ps.Close()
+		return fmt.Errorf("plugin %s: %s", ps.name(), hello.Message)
+	}
+	if hello.IntervalMs > 0 {
+		ps.interval = time.Duration(hello.IntervalMs) * time.Millisecond
+	}
+	ps.inputs = map[string]shared.SaverInput{}
+	for name, in := range hello.Inputs {
+		t, ok := inputTypes[in.Type]
+		if !ok {
+			ps.Close()
+			return fmt.Errorf("plugin %s: input %s has unknown type '%s'", ps.name(), name, in.Type)
+		}
+		ps.inputs[name] = shared.SaverInput{
+			Default:     in.Default,
+			Description: in.Description,
+			Type:        t,
+			Min:         in.Min,
+			Max:         in.Max,
+			Values:      in.Values,
+		}
+	}
+
+	width, height := ps.canvas.Size()
+	return ps.send(resizeMessage{Type: "resize", Width: width, Height: height})
+}
+
+func (ps *PluginSaver) name() string {
+	return strings.TrimPrefix(filepath.Base(ps.path), "gh-screensaver-")
+}
+
+func (ps *PluginSaver) send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := ps.stdin.Write(append(data, '\n')); err != nil {
+		return ps.failed(err)
+	}
+	return nil
+}
+
+// request sends msg, a message of type kind, and decodes the plugin's answer
+// into reply.
+func (ps *PluginSaver) request(kind string, msg interface{}, reply interface{}) error {
+	if err := ps.send(msg); err != nil {
+		return err
+	}
+	select {
+	case line, ok := <-ps.lines:
+		if !ok {
+			return ps.failed(errors.New("exited"))
+		}
+		if err := json.Unmarshal(line, reply); err != nil {
+			return fmt.Errorf("plugin %s sent something that isn't JSON: %w", ps.name(), err)
+		}
+		return nil
+	case <-time.After(pluginTimeout):
+		return fmt.Errorf("plugin %s did not answer %s within %s", ps.name(), kind, pluginTimeout)
+	}
+}
+
+// failed explains a broken connection to the plugin, with whatever it wrote
+// to stderr.
+func (ps *PluginSaver) failed(err error) error {
+	if stderr := strings.TrimSpace(ps.stderr.String()); stderr != "" {
+		return fmt.Errorf("plugin %s: %w: %s", ps.name(), err, stderr)
+	}
+	return fmt.Errorf("plugin %s: %w", ps.name(), err)
+}
+
+func (ps *PluginSaver) Inputs() map[string]shared.SaverInput {
+	return ps.inputs
+}
+
+func (ps *PluginSaver) SetInputs(inputs shared.InputValues) error {
+	values := map[string]interface{}{}
+	for name, value := range inputs {
+		switch v := value.(type) {
+		case tcell.Color:
+			if v == tcell.ColorDefault {
+				values[name] = "default"
+			} else {
+				values[name] = fmt.Sprintf("#%06x", v.Hex())
+			}
+		case time.Duration:
+			values[name] = v.String()
+		default:
+			values[name] = v
+		}
+	}
+	return ps.send(inputsMessage{Type: "inputs", Inputs: values})
+}
+
+func (ps *PluginSaver) Interval() time.Duration {
+	return ps.interval
+}
+
+func (ps *PluginSaver) Resize(width, height int) {
+	for c := range ps.cells {
+		if c.x >= width || c.y >= height {
+			delete(ps.cells, c)
+		}
+	}
+	// A plugin that has gone away will be noticed on the next tick.
+	_ = ps.send(resizeMessage{Type: "resize", Width: width, Height: height})
+}
+
+// HandleEvent passes key presses and mouse events on to the plugin. The
+// plugin doesn't answer, so there is no knowing whether it used them and
+// they are reported as unused.
+func (ps *PluginSaver) HandleEvent(ev tcell.Event) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		_ = ps.send(keyMessage{Type: "key", Key: ev.Name()})
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		_ = ps.send(mouseMessage{Type: "mouse", X: x, Y: y, Buttons: int(ev.Buttons())})
+	}
+	return false
+}
+
+func (ps *PluginSaver) Clear() {
+	ps.canvas.Clear()
+}
+
+func (ps *PluginSaver) Update(delta time.Duration) error {
+	var frame pluginFrame
+	if err := ps.request("tick", tickMessage{Type: "tick", DeltaMs: delta.Milliseconds()}, &frame); err != nil {
+		return err
+	}
+	if frame.Type == "error" {
+		return fmt.Errorf("plugin %s: %s", ps.name(), frame.Message)
+	}
+	if frame.Clear {
+		ps.cells = map[coord]pluginCell{}
+	}
+	for _, cell := range frame.Cells {
+		c := coord{x: cell.X, y: cell.Y}
+		if cell.Ch == "" {
+			delete(ps.cells, c)
+		} else {
+			ps.cells[c] = cell
+		}
+	}
+
+	for c, cell := range ps.cells {
+		ps.canvas.SetContent(c.x, c.y, []rune(cell.Ch)[0], nil, ps.cellStyle(cell))
+	}
+	return nil
+}
+
+func (ps *PluginSaver) cellStyle(cell pluginCell) tcell.Style {
+	style := ps.style
+	if cell.Fg != "" {
+		style = style.Foreground(tcell.GetColor(cell.Fg))
+	}
+	if cell.Bg != "" {
+		style = style.Background(tcell.GetColor(cell.Bg))
+	}
+	return style.Bold(cell.Bold).Underline(cell.Underline).Reverse(cell.Reverse).Dim(cell.Dim)
+}
+
+// Close stops the plugin. Savers that hold on to anything outside the
+// process implement io.Closer, and the framework closes them when they are
+// done with.
+func (ps *PluginSaver) Close() error {
+	if ps.closed {
+		return nil
+	}
+	ps.closed = true
+	close(ps.quit)
+	ps.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		_ = ps.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		_ = ps.cmd.Process.Kill()
+	}
+	return nil
+}
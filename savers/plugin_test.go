@@ -0,0 +1,26 @@
+package savers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPluginMessagesKeepZeros(t *testing.T) {
+	tests := []struct {
+		msg  interface{}
+		want string
+	}{
+		{helloMessage{Type: "hello"}, `{"type":"hello","seed":0}`},
+		{tickMessage{Type: "tick"}, `{"type":"tick","delta_ms":0}`},
+		{mouseMessage{Type: "mouse"}, `{"type":"mouse","x":0,"y":0,"buttons":0}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
@@ -8,9 +8,19 @@ import (
 	"github.com/vilmibm/gh-screensaver/savers/shared"
 )
 
+var PollockMetadata = shared.SaverMetadata{
+	Description: "Paint splotches cover the screen.",
+	Author:      "nate smith",
+	MinWidth:    1,
+	MinHeight:   1,
+}
+
 type PollockSaver struct {
-	screen tcell.Screen
-	style  tcell.Style
+	canvas   *shared.Canvas
+	style    tcell.Style
+	interval time.Duration
+	rand     *rand.Rand
+	stepper  *shared.Stepper
 
 	width  int
 	height int
@@ -36,22 +46,32 @@ func NewPollockSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
 
 func (p *PollockSaver) Clear() {}
 
+func (p *PollockSaver) Interval() time.Duration {
+	return p.interval
+}
+
 func (p *PollockSaver) Initialize(opts shared.ScreensaverOpts) error {
-	p.screen = opts.Screen
+	p.canvas = opts.Canvas
 	p.style = opts.Style
-	p.width, p.height = p.screen.Size()
+	p.rand = opts.Rand
+	p.interval = shared.DefaultInterval
+	p.stepper = &shared.Stepper{Every: 100 * time.Millisecond}
+	p.width, p.height = p.canvas.Size()
 
 	p.maxSplats = 1000
 
-	rand.Seed(time.Now().UTC().UnixNano())
 	return nil
 }
 
+func (p *PollockSaver) Resize(width, height int) {
+	p.width, p.height = width, height
+}
+
 func (p *PollockSaver) Inputs() map[string]shared.SaverInput {
 	return map[string]shared.SaverInput{}
 }
 
-func (p *PollockSaver) SetInputs(inputs map[string]string) error {
+func (p *PollockSaver) SetInputs(inputs shared.InputValues) error {
 	return nil
 }
 
@@ -66,13 +86,13 @@ type splat struct {
 	cells []paintCell
 }
 
-func newSplat(width, height int) *splat {
+func newSplat(r *rand.Rand, width, height int) *splat {
 	s := &splat{
-		color: randColor(),
+		color: randColor(r),
 	}
 
 	c := "#"
-	cChance := rand.Intn(10)
+	cChance := r.Intn(10)
 	if cChance > 8 {
 		c = "+"
 	} else if cChance > 4 {
@@ -80,8 +100,8 @@ func newSplat(width, height int) *splat {
 	}
 
 	cell := paintCell{
-		x:    rand.Intn(width),
-		y:    rand.Intn(height),
+		x:    r.Intn(width),
+		y:    r.Intn(height),
 		char: c,
 	}
 
@@ -90,7 +110,7 @@ func newSplat(width, height int) *splat {
 	return s
 }
 
-func (s *splat) Spread(width, height int) {
+func (s *splat) Spread(r *rand.Rand, width, height int) {
 	last := s.cells[len(s.cells)-1]
 
 	all := []paintCell{}
@@ -112,9 +132,9 @@ func (s *splat) Spread(width, height int) {
 		return
 	}
 
-	next := all[rand.Intn(len(all))]
+	next := all[r.Intn(len(all))]
 	next.char = "#"
-	cChance := rand.Intn(10)
+	cChance := r.Intn(10)
 	if cChance > 8 {
 		next.char = "+"
 	} else if cChance > 4 {
@@ -123,26 +143,34 @@ func (s *splat) Spread(width, height int) {
 	s.cells = append(s.cells, next)
 }
 
-func (p *PollockSaver) Update() error {
-	if rand.Intn(10) > 5 {
-		return nil
+func (p *PollockSaver) Update(delta time.Duration) error {
+	for i := p.stepper.Steps(delta); i > 0; i-- {
+		p.step()
+	}
+
+	for _, splat := range p.splats {
+		for _, cell := range splat.cells {
+			p.canvas.DrawString(cell.x, cell.y, p.style.Foreground(splat.color), cell.char)
+		}
+	}
+
+	return nil
+}
+
+func (p *PollockSaver) step() {
+	if p.rand.Intn(10) > 5 {
+		return
 	}
 
-	p.splats = append(p.splats, newSplat(p.width, p.height))
+	p.splats = append(p.splats, newSplat(p.rand, p.width, p.height))
 
 	if len(p.splats) > p.maxSplats {
 		p.splats = p.splats[1:]
 	}
 
 	for _, splat := range p.splats {
-		if rand.Intn(10) < 5 {
-			splat.Spread(p.width, p.height)
-		}
-
-		for _, cell := range splat.cells {
-			drawStr(p.screen, cell.x, cell.y, p.style.Foreground(splat.color), cell.char)
+		if p.rand.Intn(10) < 5 {
+			splat.Spread(p.rand, p.width, p.height)
 		}
 	}
-
-	return nil
 }
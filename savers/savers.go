@@ -1 +1,12 @@
 package savers
+
+import "github.com/vilmibm/gh-screensaver/savers/shared"
+
+// colorModeInput is the --color input shared by savers that can also draw in
+// monochrome.
+var colorModeInput = shared.SaverInput{
+	Default:     "full",
+	Description: "Whether to use full color or monochrome",
+	Type:        shared.InputEnum,
+	Values:      []string{"full", "off"},
+}
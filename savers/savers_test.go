@@ -0,0 +1,227 @@
+package savers
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+type goldenCase struct {
+	name    string
+	creator shared.SaverCreator
+	inputs  map[string]string
+	width   int
+	height  int
+	frames  int
+	// events are handed to the saver before the first frame.
+	events []tcell.Event
+}
+
+var goldenCases = []goldenCase{
+	{name: "fireworks", creator: NewFireworksSaver},
+	{name: "fireworks-color-off", creator: NewFireworksSaver, inputs: map[string]string{"color": "off"}},
+	{name: "marquee", creator: NewMarqueeSaver},
+	{name: "marquee-font-script", creator: NewMarqueeSaver, inputs: map[string]string{"font": "script", "message": "hello world"}},
+	{name: "marquee-font-banner", creator: NewMarqueeSaver, inputs: map[string]string{"font": "banner", "speed": "25"}},
+	{name: "pipes", creator: NewPipesSaver},
+	{name: "pipes-color-off", creator: NewPipesSaver, inputs: map[string]string{"color": "off"}},
+	{name: "pollock", creator: NewPollockSaver},
+	{name: "starfield", creator: NewStarfieldSaver},
+	{name: "starfield-sparse-fast", creator: NewStarfieldSaver, inputs: map[string]string{"density": "50", "speed": "10"}},
+	{name: "life-dragon", creator: NewLifeSaver, inputs: map[string]string{"seed": "dragon"}, height: 45},
+	{name: "life-gun", creator: NewLifeSaver, inputs: map[string]string{"seed": "gun"}, height: 45},
+	{name: "life-noise", creator: NewLifeSaver, inputs: map[string]string{"seed": "noise"}, height: 45},
+	{name: "life-r", creator: NewLifeSaver, inputs: map[string]string{"seed": "R"}, height: 45},
+	{name: "life-pulsar", creator: NewLifeSaver, inputs: map[string]string{"seed": "pulsar"}, height: 45},
+	{name: "life-glider", creator: NewLifeSaver, inputs: map[string]string{"seed": "glider"}, height: 45},
+	{name: "life-color-off", creator: NewLifeSaver, inputs: map[string]string{"seed": "pulsar", "color": "off"}, height: 45},
+	{name: "life-toggled", creator: NewLifeSaver, inputs: map[string]string{"seed": "glider"}, height: 45, frames: 3, events: []tcell.Event{
+		tcell.NewEventMouse(60, 30, tcell.Button1, tcell.ModNone),
+		tcell.NewEventMouse(61, 30, tcell.Button1, tcell.ModNone),
+		tcell.NewEventMouse(62, 30, tcell.Button1, tcell.ModNone),
+		tcell.NewEventMouse(62, 30, tcell.ButtonNone, tcell.ModNone),
+	}},
+	{name: "fireworks-click", creator: NewFireworksSaver, inputs: map[string]string{"color": "off"}, frames: 14, events: []tcell.Event{
+		tcell.NewEventMouse(40, 12, tcell.Button1, tcell.ModNone),
+	}},
+	// Dragging launches one firework, from where the button went down.
+	{name: "fireworks-drag", creator: NewFireworksSaver, inputs: map[string]string{"color": "off"}, frames: 14, events: []tcell.Event{
+		tcell.NewEventMouse(40, 12, tcell.Button1, tcell.ModNone),
+		tcell.NewEventMouse(50, 12, tcell.Button1, tcell.ModNone),
+		tcell.NewEventMouse(60, 12, tcell.Button1, tcell.ModNone),
+		tcell.NewEventMouse(60, 12, tcell.ButtonNone, tcell.ModNone),
+	}},
+	{name: "plugin-stripes", creator: NewPluginSaver("testdata/gh-screensaver-stripes"), inputs: map[string]string{"color": "#00ff00"}, width: 20, height: 5, frames: 12},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderFrames(tc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v; run go test ./savers -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("frame does not match %s\n%s", path, firstDifference(string(want), got))
+			}
+		})
+	}
+}
+
+// renderFrames runs a saver on a simulation screen with a fixed seed, giving
+// every frame exactly the saver's own interval of time, and returns the
+// final frame in golden file form.
+func renderFrames(tc goldenCase) (string, error) {
+	width, height, frames := tc.width, tc.height, tc.frames
+	if width == 0 {
+		width = 80
+	}
+	if height == 0 {
+		height = 24
+	}
+	if frames == 0 {
+		frames = 40
+	}
+
+	canvas := shared.NewCanvas(width, height)
+	saver, err := tc.creator(shared.ScreensaverOpts{
+		Canvas: canvas,
+		Style:  tcell.StyleDefault,
+		Seed:   42,
+		Rand:   rand.New(rand.NewSource(42)),
+	})
+	if err != nil {
+		return "", err
+	}
+	if c, ok := saver.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	inputs := map[string]string{}
+	for name, input := range saver.Inputs() {
+		inputs[name] = input.Default
+	}
+	for name, value := range tc.inputs {
+		if _, ok := inputs[name]; !ok {
+			return "", fmt.Errorf("saver has no input named %s", name)
+		}
+		inputs[name] = value
+	}
+	values, err := shared.ParseInputs(saver.Inputs(), inputs)
+	if err != nil {
+		return "", err
+	}
+	if err := saver.SetInputs(values); err != nil {
+		return "", err
+	}
+
+	for _, ev := range tc.events {
+		h, ok := saver.(shared.EventHandler)
+		if !ok {
+			return "", fmt.Errorf("saver does not handle events")
+		}
+		h.HandleEvent(ev)
+	}
+
+	for i := 0; i < frames; i++ {
+		saver.Clear()
+		if err := saver.Update(saver.Interval()); err != nil {
+			return "", err
+		}
+	}
+
+	return snapshot(canvas), nil
+}
+
+// snapshot renders the canvas as its runes, followed by each row's styles
+// run-length encoded as index*count, followed by the style each index
+// stands for.
+func snapshot(canvas *shared.Canvas) string {
+	width, height := canvas.Size()
+
+	var runes, styles strings.Builder
+	legend := []tcell.Style{}
+	index := map[tcell.Style]int{}
+	for y := 0; y < height; y++ {
+		runes.WriteString("|")
+		runs := []string{}
+		prev, count := -1, 0
+		for x := 0; x < width; x++ {
+			cell := canvas.Cell(x, y)
+			r := ' '
+			if cell.Set() {
+				r = cell.Rune
+			}
+			runes.WriteRune(r)
+
+			ix, ok := index[cell.Style]
+			if !ok {
+				ix = len(legend)
+				index[cell.Style] = ix
+				legend = append(legend, cell.Style)
+			}
+			if ix != prev && count > 0 {
+				runs = append(runs, fmt.Sprintf("%d*%d", prev, count))
+				count = 0
+			}
+			prev = ix
+			count++
+		}
+		runs = append(runs, fmt.Sprintf("%d*%d", prev, count))
+		runes.WriteString("|\n")
+		styles.WriteString(strings.Join(runs, " ") + "\n")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "size %dx%d\n", width, height)
+	out.WriteString(runes.String())
+	out.WriteString("styles\n")
+	out.WriteString(styles.String())
+	for ix, style := range legend {
+		fg, bg, attrs := style.Decompose()
+		fmt.Fprintf(&out, "%d: fg=%s bg=%s attrs=%d\n", ix, colorName(fg), colorName(bg), attrs)
+	}
+	return out.String()
+}
+
+func colorName(c tcell.Color) string {
+	if c == tcell.ColorDefault {
+		return "default"
+	}
+	return fmt.Sprintf("#%06x", c.Hex())
+}
+
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) && i < len(gotLines); i++ {
+		if wantLines[i] != gotLines[i] {
+			return fmt.Sprintf("line %d:\nwant: %s\n got: %s", i+1, wantLines[i], gotLines[i])
+		}
+	}
+	return fmt.Sprintf("want %d lines, got %d", len(wantLines), len(gotLines))
+}
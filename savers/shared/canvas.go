@@ -0,0 +1,175 @@
+package shared
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// Cell is one character cell of a Canvas. The zero Cell is unset: nothing
+// has been drawn there, and whatever is behind the canvas shows through.
+type Cell struct {
+	Rune  rune
+	Comb  []rune
+	Style tcell.Style
+}
+
+// Set reports whether anything has been drawn in the cell.
+func (c Cell) Set() bool {
+	return c.Rune != 0
+}
+
+// Canvas is what a saver draws on. The framework copies it to the terminal
+// after every frame, so a saver never touches the terminal itself.
+//
+// A canvas can also be a view onto part of another, made with Sub. A view
+// draws on its parent's cells, at its own origin, and clips what is drawn on
+// it to its own bounds and its parent's. Drawing outside a canvas does
+// nothing, but is counted so that it can be reported when debugging.
+type Canvas struct {
+	// cells belong to a canvas made with NewCanvas. A view has a parent
+	// instead, and x and y are where its origin is in the parent.
+	cells         []Cell
+	parent        *Canvas
+	x, y          int
+	width, height int
+	outOfBounds   int
+}
+
+// NewCanvas returns an empty canvas of the given size.
+func NewCanvas(width, height int) *Canvas {
+	c := &Canvas{}
+	c.SetSize(width, height)
+	return c
+}
+
+// Size returns the canvas's width and height in cells.
+func (c *Canvas) Size() (int, int) {
+	return c.width, c.height
+}
+
+// SetSize changes the size of the canvas, keeping whatever is drawn in the
+// part that is left. A view only changes its own bounds; its parent stays
+// the size it is, and still clips it.
+func (c *Canvas) SetSize(width, height int) {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	if c.parent == nil {
+		cells := make([]Cell, width*height)
+		for y := 0; y < height && y < c.height; y++ {
+			copy(cells[y*width:y*width+width], c.cells[y*c.width:y*c.width+c.width])
+		}
+		c.cells = cells
+	}
+	c.width, c.height = width, height
+}
+
+// Move puts a view's top left corner at x, y of its parent. It does nothing
+// to a canvas that isn't a view.
+func (c *Canvas) Move(x, y int) {
+	if c.parent != nil {
+		c.x, c.y = x, y
+	}
+}
+
+// cell returns the cell at x, y, or nil if that is outside the canvas or
+// any canvas it is a view onto.
+func (c *Canvas) cell(x, y int) *Cell {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		return nil
+	}
+	if c.parent != nil {
+		return c.parent.cell(c.x+x, c.y+y)
+	}
+	return &c.cells[y*c.width+x]
+}
+
+// SetContent draws a character, with any combining characters, at x, y.
+// It takes the same arguments as tcell.Screen's SetContent.
+func (c *Canvas) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	cell := c.cell(x, y)
+	if cell == nil {
+		c.outOfBounds++
+		return
+	}
+	var comb []rune
+	if len(combc) > 0 {
+		comb = append([]rune{}, combc...)
+	}
+	*cell = Cell{Rune: mainc, Comb: comb, Style: style}
+}
+
+// SetCell puts cell at x, y. Setting the zero Cell unsets it.
+func (c *Canvas) SetCell(x, y int, cell Cell) {
+	c.SetContent(x, y, cell.Rune, cell.Comb, cell.Style)
+}
+
+// Cell returns the cell at x, y, which is unset if it is outside the canvas.
+func (c *Canvas) Cell(x, y int) Cell {
+	if cell := c.cell(x, y); cell != nil {
+		return *cell
+	}
+	return Cell{}
+}
+
+// Clear unsets every cell of the canvas.
+func (c *Canvas) Clear() {
+	for y := 0; y < c.height; y++ {
+		for x := 0; x < c.width; x++ {
+			if cell := c.cell(x, y); cell != nil {
+				*cell = Cell{}
+			}
+		}
+	}
+}
+
+// DrawString draws str starting at x, y, a character at a time. Zero width
+// characters are drawn combined with a space so that they still take a cell.
+func (c *Canvas) DrawString(x, y int, style tcell.Style, str string) {
+	for _, r := range str {
+		var comb []rune
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			comb = []rune{r}
+			r = ' '
+			w = 1
+		}
+		c.SetContent(x, y, r, comb, style)
+		x += w
+	}
+}
+
+// Sub returns a view of the width x height region of c whose top left corner
+// is at x, y. The view is clipped to c, so drawing on it never reaches
+// outside of c.
+func (c *Canvas) Sub(x, y, width, height int) *Canvas {
+	if x < 0 {
+		width, x = width+x, 0
+	}
+	if y < 0 {
+		height, y = height+y, 0
+	}
+	if x+width > c.width {
+		width = c.width - x
+	}
+	if y+height > c.height {
+		height = c.height - y
+	}
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	return &Canvas{parent: c, x: x, y: y, width: width, height: height}
+}
+
+// OutOfBounds returns how many times something was drawn outside the
+// canvas. Savers aren't expected to keep inside it, so this is only of
+// interest when debugging one.
+func (c *Canvas) OutOfBounds() int {
+	return c.outOfBounds
+}
@@ -0,0 +1,90 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestCanvas(t *testing.T) {
+	c := NewCanvas(4, 3)
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+
+	c.SetContent(1, 2, 'x', nil, red)
+	if got := c.Cell(1, 2); got.Rune != 'x' || got.Style != red {
+		t.Errorf("got %+v, want a red x", got)
+	}
+	if c.Cell(0, 0).Set() {
+		t.Error("a cell nothing was drawn in is set")
+	}
+
+	c.SetContent(4, 0, 'x', nil, red)
+	c.SetContent(-1, 1, 'x', nil, red)
+	c.DrawString(2, 0, red, "abc")
+	if got := c.OutOfBounds(); got != 3 {
+		t.Errorf("got %d out of bounds writes, want 3", got)
+	}
+	if got := c.Cell(3, 0).Rune; got != 'b' {
+		t.Errorf("got %q at the end of the string, want b", got)
+	}
+
+	c.SetSize(2, 3)
+	if got := c.Cell(1, 2).Rune; got != 'x' {
+		t.Errorf("got %q after shrinking, want the x kept", got)
+	}
+	c.Clear()
+	if c.Cell(1, 2).Set() {
+		t.Error("Clear left a cell set")
+	}
+}
+
+func TestCanvasSub(t *testing.T) {
+	c := NewCanvas(10, 5)
+	sub := c.Sub(6, 1, 6, 3)
+	if w, h := sub.Size(); w != 4 || h != 3 {
+		t.Fatalf("got a %dx%d view, want it clipped to 4x3", w, h)
+	}
+
+	sub.SetContent(0, 0, 'a', nil, tcell.StyleDefault)
+	sub.SetContent(4, 0, 'b', nil, tcell.StyleDefault)
+	sub.SetContent(0, 3, 'c', nil, tcell.StyleDefault)
+	if got := c.Cell(6, 1).Rune; got != 'a' {
+		t.Errorf("got %q, want the view's origin at 6,1", got)
+	}
+	if got := sub.OutOfBounds(); got != 2 {
+		t.Errorf("got %d out of bounds writes on the view, want 2", got)
+	}
+	if got := c.OutOfBounds(); got != 0 {
+		t.Errorf("got %d out of bounds writes on the parent, want 0", got)
+	}
+
+	c.SetContent(0, 0, 'z', nil, tcell.StyleDefault)
+	sub.Clear()
+	if c.Cell(6, 1).Set() {
+		t.Error("clearing the view left its cells set")
+	}
+	if !c.Cell(0, 0).Set() {
+		t.Error("clearing the view cleared outside it")
+	}
+
+	inner := sub.Sub(1, 1, 10, 10)
+	inner.SetContent(0, 0, 'd', nil, tcell.StyleDefault)
+	if got := c.Cell(7, 2).Rune; got != 'd' {
+		t.Errorf("got %q, want a view of a view to be offset by both", got)
+	}
+	if w, h := inner.Size(); w != 3 || h != 2 {
+		t.Errorf("got a %dx%d view of a view, want 3x2", w, h)
+	}
+
+	sub.Move(0, 0)
+	inner.SetContent(0, 0, 'e', nil, tcell.StyleDefault)
+	if got := c.Cell(1, 1).Rune; got != 'e' {
+		t.Errorf("got %q, want moving a view to move the views of it", got)
+	}
+	// A view made bigger than its parent is still clipped by it.
+	sub.SetSize(20, 20)
+	sub.SetContent(12, 0, 'f', nil, tcell.StyleDefault)
+	if got := sub.OutOfBounds(); got != 3 {
+		t.Errorf("got %d out of bounds writes on the view, want 3", got)
+	}
+}
@@ -0,0 +1,186 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// InputType says what kind of value a SaverInput takes. The framework checks
+// and converts values according to it before they reach SetInputs.
+type InputType int
+
+const (
+	InputString InputType = iota
+	InputInt
+	InputFloat
+	InputBool
+	InputEnum
+	InputColor
+	InputDuration
+	InputPath
+)
+
+func (t InputType) String() string {
+	switch t {
+	case InputInt:
+		return "int"
+	case InputFloat:
+		return "float"
+	case InputBool:
+		return "bool"
+	case InputEnum:
+		return "enum"
+	case InputColor:
+		return "color"
+	case InputDuration:
+		return "duration"
+	case InputPath:
+		return "path"
+	default:
+		return "string"
+	}
+}
+
+type SaverInput struct {
+	Default     string
+	Description string
+	Type        InputType
+	// Min and Max bound InputInt and InputFloat values. They are ignored if
+	// they are equal.
+	Min float64
+	Max float64
+	// Values lists what an InputEnum accepts. Matching ignores case.
+	Values []string
+}
+
+// Parse checks value against the input's type and converts it: to a string,
+// int, float64, bool, tcell.Color or time.Duration. An empty InputPath or
+// InputColor means unset and is always allowed.
+func (i SaverInput) Parse(name, value string) (interface{}, error) {
+	invalid := func(why string) error {
+		return fmt.Errorf("invalid value %q for --%s: %s", value, name, why)
+	}
+	inRange := func(n float64) error {
+		if i.Min != i.Max && (n < i.Min || n > i.Max) {
+			return invalid(fmt.Sprintf("must be between %s and %s",
+				strconv.FormatFloat(i.Min, 'f', -1, 64), strconv.FormatFloat(i.Max, 'f', -1, 64)))
+		}
+		return nil
+	}
+
+	switch i.Type {
+	case InputInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, invalid("must be a whole number")
+		}
+		return n, inRange(float64(n))
+	case InputFloat:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, invalid("must be a number")
+		}
+		return n, inRange(n)
+	case InputBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalid("must be true or false")
+		}
+		return b, nil
+	case InputEnum:
+		for _, v := range i.Values {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+		return nil, invalid("must be one of " + strings.Join(i.Values, ", "))
+	case InputColor:
+		if value == "" || value == "default" {
+			return tcell.ColorDefault, nil
+		}
+		c := tcell.GetColor(strings.ToLower(value))
+		if c == tcell.ColorDefault {
+			return nil, invalid(`must be a color name like "red" or a hex color like "#ff8800"`)
+		}
+		return c, nil
+	case InputDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, invalid(`must be a duration like "30s" or "5m"`)
+		}
+		if d < 0 {
+			return nil, invalid("must not be negative")
+		}
+		return d, nil
+	case InputPath:
+		if value == "" {
+			return value, nil
+		}
+		info, err := os.Stat(value)
+		if err != nil {
+			return nil, invalid("no such file")
+		}
+		if info.IsDir() {
+			return nil, invalid("is a directory")
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// InputValues holds checked and converted inputs, keyed by input name.
+type InputValues map[string]interface{}
+
+// ParseInputs checks every raw value against its definition in inputs,
+// falling back to the input's default for any value that wasn't given.
+func ParseInputs(inputs map[string]SaverInput, raw map[string]string) (InputValues, error) {
+	values := InputValues{}
+	for name, input := range inputs {
+		value, ok := raw[name]
+		if !ok {
+			value = input.Default
+		}
+		v, err := input.Parse(name, value)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = v
+	}
+	return values, nil
+}
+
+func (v InputValues) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+func (v InputValues) Int(name string) int {
+	n, _ := v[name].(int)
+	return n
+}
+
+func (v InputValues) Float(name string) float64 {
+	n, _ := v[name].(float64)
+	return n
+}
+
+func (v InputValues) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+func (v InputValues) Color(name string) tcell.Color {
+	c, _ := v[name].(tcell.Color)
+	return c
+}
+
+func (v InputValues) Duration(name string) time.Duration {
+	d, _ := v[name].(time.Duration)
+	return d
+}
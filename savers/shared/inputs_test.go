@@ -0,0 +1,58 @@
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestSaverInputParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   SaverInput
+		value   string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "string", input: SaverInput{}, value: "hi", want: "hi"},
+		{name: "int", input: SaverInput{Type: InputInt, Min: 0, Max: 10}, value: "3", want: 3},
+		{name: "int below min", input: SaverInput{Type: InputInt, Min: 0, Max: 10}, value: "-3",
+			wantErr: `invalid value "-3" for --x: must be between 0 and 10`},
+		{name: "int not a number", input: SaverInput{Type: InputInt}, value: "1.5",
+			wantErr: `invalid value "1.5" for --x: must be a whole number`},
+		{name: "int unbounded", input: SaverInput{Type: InputInt}, value: "-3", want: -3},
+		{name: "float", input: SaverInput{Type: InputFloat, Min: 0, Max: 1}, value: "0.5", want: 0.5},
+		{name: "bool", input: SaverInput{Type: InputBool}, value: "true", want: true},
+		{name: "enum", input: SaverInput{Type: InputEnum, Values: []string{"full", "off"}}, value: "OFF", want: "off"},
+		{name: "enum invalid", input: SaverInput{Type: InputEnum, Values: []string{"full", "off"}}, value: "ful",
+			wantErr: `invalid value "ful" for --x: must be one of full, off`},
+		{name: "color name", input: SaverInput{Type: InputColor}, value: "Red", want: tcell.ColorRed},
+		{name: "color hex", input: SaverInput{Type: InputColor}, value: "#ff8800", want: tcell.NewHexColor(0xff8800)},
+		{name: "color default", input: SaverInput{Type: InputColor}, value: "default", want: tcell.ColorDefault},
+		{name: "color invalid", input: SaverInput{Type: InputColor}, value: "blurple",
+			wantErr: `invalid value "blurple" for --x: must be a color name like "red" or a hex color like "#ff8800"`},
+		{name: "duration", input: SaverInput{Type: InputDuration}, value: "90s", want: 90 * time.Second},
+		{name: "path unset", input: SaverInput{Type: InputPath}, value: "", want: ""},
+		{name: "path missing", input: SaverInput{Type: InputPath}, value: "testdata/nope",
+			wantErr: `invalid value "testdata/nope" for --x: no such file`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.Parse("x", tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -1,28 +1,156 @@
 package shared
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"io"
+	"math/rand"
+	"time"
 
-type SaverInput struct {
-	Default     string
-	Description string // TODO do i even want
-}
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultInterval is the frame interval savers use unless they have a reason
+// to want something else.
+const DefaultInterval = 100 * time.Millisecond
 
 type Screensaver interface {
 	Initialize(opts ScreensaverOpts) error
-	SetInputs(map[string]string) error
-	Update() error
+	// SetInputs is given every input from Inputs, already checked against
+	// its type and converted.
+	SetInputs(InputValues) error
+	// Update draws the next frame. delta is the real time that has passed
+	// since the previous frame; savers should scale their motion by it rather
+	// than assuming a fixed frame rate.
+	Update(delta time.Duration) error
 	Inputs() map[string]SaverInput
 	Clear()
+	// Interval is how long the saver would like between frames. It is asked
+	// before every frame, so a saver is free to change it while running.
+	Interval() time.Duration
+	// Resize is called when the terminal changes size, before the next frame
+	// is drawn. It is never given a size smaller than the saver's minimum.
+	Resize(width, height int)
+}
+
+// EventHandler is implemented by savers that react to the keyboard or mouse.
+// HandleEvent is given *tcell.EventKey and *tcell.EventMouse events from the
+// render loop, between frames, so it never runs at the same time as Update.
+// It reports whether the saver used the event; a key the saver doesn't use
+// may be taken by the framework's own controls.
+type EventHandler interface {
+	HandleEvent(ev tcell.Event) bool
+}
+
+// MinSizer is implemented by savers whose minimum size depends on their
+// inputs, such as a pattern or a font. MinSize is asked after SetInputs and
+// after every Resize, and takes the place of the minimum in the saver's
+// metadata. Such a saver may be created on a screen smaller than this; it
+// isn't updated until the screen is big enough, and Resize is called when it
+// is.
+type MinSizer interface {
+	MinSize() (width, height int)
 }
 
 type SaverCreator func(ScreensaverOpts) (Screensaver, error)
 
+// SaverMetadata is what users are told about a saver in help output.
+type SaverMetadata struct {
+	Description string
+	Author      string
+	// MinWidth and MinHeight are the smallest terminal the saver works in.
+	// It is only ever created on a screen at least this big, and while the
+	// terminal is smaller the framework pauses it and says so.
+	MinWidth  int
+	MinHeight int
+}
+
+// RegisteredSaver is a saver as it is offered to users.
+type RegisteredSaver struct {
+	Create   SaverCreator
+	Metadata SaverMetadata
+}
+
 type ScreensaverOpts struct {
 	Screensaver string
 	Repository  string
 	List        bool
 	Style       tcell.Style
-	Screen      tcell.Screen
-	Savers      map[string]SaverCreator
-	SaverArgs   []string
+	// Canvas is what the saver draws on.
+	Canvas    *Canvas
+	Savers    map[string]RegisteredSaver
+	SaverArgs []string
+	FPS       int
+	Headless  bool
+	// Out is where Headless output goes. It defaults to stdout.
+	Out        io.Writer
+	Frames     int
+	Size       string
+	Format     string
+	AllFrames  bool
+	Record     string
+	Rotate     time.Duration
+	Playlist   []string
+	Shuffle    bool
+	Transition string
+	// TransitionDuration is how long Transition takes.
+	TransitionDuration time.Duration
+	// Mosaic tiles several savers side by side instead of running one. It is
+	// either COLSxROWS, filled with savers picked at random, or a comma
+	// separated list of savers. Labels puts each one's name over its tile.
+	Mosaic string
+	Labels bool
+	// Layers runs these savers one over the other, the first at the bottom.
+	// Whatever a saver leaves unset shows the ones below it.
+	Layers []string
+	// Lenient ignores saver arguments that a saver has no input for, rather
+	// than treating them as a mistake.
+	Lenient bool
+	// Controls lets keys pause, skip and speed up savers instead of any key
+	// quitting.
+	Controls bool
+	// Exit is what input ends the session: key, motion or chord. ExitChord
+	// is the chord for the chord policy, like ctrl+q.
+	Exit      string
+	ExitChord string
+	// Duration, if set, ends the session after that long.
+	Duration time.Duration
+	// Lock keeps the session going until someone types the passphrase whose
+	// bcrypt hash is LockHash. Exit and Duration don't apply.
+	Lock     bool
+	LockHash string
+	// Debug reports how many cells each saver drew outside its canvas once
+	// the session is over.
+	Debug bool
+	// CrashReport, if set, is a file to write the details to if a saver
+	// panics.
+	CrashReport string
+	// InputDefaults replaces the defaults of savers' inputs, by saver name
+	// and then input name. It comes from the config file.
+	InputDefaults map[string]map[string]string
+	// ColorMode, if set, is the default for every saver's --color input.
+	ColorMode string
+	// Seed is what Rand was seeded with, kept so a run can be reproduced.
+	Seed int64
+	// Rand is the saver's own source of randomness. Savers must draw from it
+	// rather than the global math/rand so that a seed reproduces a run.
+	Rand *rand.Rand
+}
+
+// Stepper turns elapsed time into a whole number of fixed-size simulation
+// steps, carrying any remainder over to the next call. Savers whose state
+// advances in discrete steps (a generation, a cell of pipe) use it so that
+// they run at the same speed no matter how often they are drawn.
+type Stepper struct {
+	Every time.Duration
+	acc   time.Duration
+}
+
+// Steps adds delta to the elapsed time and returns how many steps are due.
+func (s *Stepper) Steps(delta time.Duration) int {
+	if s.Every <= 0 {
+		return 0
+	}
+	s.acc += delta
+	n := int(s.acc / s.Every)
+	s.acc -= time.Duration(n) * s.Every
+	return n
 }
@@ -1,10 +1,8 @@
 package savers
 
 import (
-	"fmt"
 	"math"
 	"math/rand"
-	"strconv"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -15,9 +13,31 @@ import (
 
 const deg2rad = math.Pi / 180.0
 
+// steerStep is how much each press of an arrow key adds to the sideways
+// drift, in units per second, and steerHalfLife how long the drift takes to
+// die down by half once the keys are let go.
+const (
+	steerStep     = 1.5
+	maxSteer      = 6.0
+	steerHalfLife = time.Second
+)
+
+// depthPerSpeed is how far, in units of depth, stars travel each second for
+// every point of --speed.
+const depthPerSpeed = 0.4
+
+var StarfieldMetadata = shared.SaverMetadata{
+	Description: "Fly through space. Inspired by asciifield.",
+	Author:      "nate smith",
+	MinWidth:    10,
+	MinHeight:   5,
+}
+
 type StarfieldSaver struct {
-	screen tcell.Screen
-	style  tcell.Style
+	canvas   *shared.Canvas
+	style    tcell.Style
+	interval time.Duration
+	rand     *rand.Rand
 
 	width  int
 	height int
@@ -33,6 +53,11 @@ type StarfieldSaver struct {
 	speed    float64
 	maxStars int
 
+	// driftX and driftY move every star sideways, in units per second, to
+	// steer through the field.
+	driftX float64
+	driftY float64
+
 	stars []*star
 }
 
@@ -47,20 +72,32 @@ func NewStarfieldSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error)
 }
 
 func (s *StarfieldSaver) Clear() {
-	s.screen.Clear()
+	s.canvas.Clear()
+}
+
+func (s *StarfieldSaver) Interval() time.Duration {
+	return s.interval
 }
 
 func (s *StarfieldSaver) Initialize(opts shared.ScreensaverOpts) error {
-	s.screen = opts.Screen
+	s.canvas = opts.Canvas
 	s.style = opts.Style
-
-	s.width, s.height = s.screen.Size()
+	s.rand = opts.Rand
+	s.interval = shared.DefaultInterval
 
 	s.n = 0.1
 	s.f = 10.0
 	s.fontAspect = 0.5
-	s.projAspect = float64(s.width) / float64(s.height) * s.fontAspect
 	s.theta = 45 * deg2rad
+	s.Resize(s.canvas.Size())
+
+	return nil
+}
+
+// Resize rebuilds the projection so the field fills the new screen.
+func (s *StarfieldSaver) Resize(width, height int) {
+	s.width, s.height = width, height
+	s.projAspect = float64(s.width) / float64(s.height) * s.fontAspect
 
 	s.projMatrix = [16]float64{
 		1.0 / math.Tan(s.theta*0.5) / s.projAspect,
@@ -78,10 +115,6 @@ func (s *StarfieldSaver) Initialize(opts shared.ScreensaverOpts) error {
 		(2 * s.n * s.f) / (s.f - s.n),
 		0,
 	}
-
-	rand.Seed(time.Now().UTC().UnixNano())
-
-	return nil
 }
 
 func (s *StarfieldSaver) Inputs() map[string]shared.SaverInput {
@@ -89,28 +122,23 @@ func (s *StarfieldSaver) Inputs() map[string]shared.SaverInput {
 		"speed": {
 			Default:     "4",
 			Description: "How fast to fly through space",
+			Type:        shared.InputFloat,
+			Min:         0,
+			Max:         100,
 		},
 		"density": {
 			Default:     "250",
 			Description: "Maximum number of stars to draw",
+			Type:        shared.InputInt,
+			Min:         0,
+			Max:         10000,
 		},
 	}
 }
 
-func (s *StarfieldSaver) SetInputs(inputs map[string]string) error {
-	speed, err := strconv.ParseFloat(inputs["speed"], 64)
-	if err != nil {
-		return fmt.Errorf("could not understand speed value: %w", err)
-	}
-
-	s.speed = speed
-
-	m, err := strconv.Atoi(inputs["density"])
-	if err != nil {
-		return fmt.Errorf("could not understand density value: %w", err)
-	}
-
-	s.maxStars = m
+func (s *StarfieldSaver) SetInputs(inputs shared.InputValues) error {
+	s.speed = inputs.Float("speed")
+	s.maxStars = inputs.Int("density")
 
 	return nil
 }
@@ -138,22 +166,48 @@ func (s *star) Project(m [16]float64) [4]float64 {
 	return out
 }
 
-func newStar(projAspect float64, f float64) *star {
+func newStar(r *rand.Rand, projAspect float64, f float64) *star {
 	return &star{
 		vec: []float64{
-			(rand.Float64()*2 - 1) * 4 * projAspect,
-			(rand.Float64()*2 - 1) * 4,
+			(r.Float64()*2 - 1) * 4 * projAspect,
+			(r.Float64()*2 - 1) * 4,
 			float64(-f),
 			1.0,
 		},
 	}
 }
 
-func (s *StarfieldSaver) Update() error {
+// HandleEvent steers with the arrow keys.
+func (s *StarfieldSaver) HandleEvent(ev tcell.Event) bool {
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	switch kev.Key() {
+	case tcell.KeyLeft:
+		s.driftX = math.Min(s.driftX+steerStep, maxSteer)
+	case tcell.KeyRight:
+		s.driftX = math.Max(s.driftX-steerStep, -maxSteer)
+	case tcell.KeyUp:
+		s.driftY = math.Max(s.driftY-steerStep, -maxSteer)
+	case tcell.KeyDown:
+		s.driftY = math.Min(s.driftY+steerStep, maxSteer)
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *StarfieldSaver) Update(delta time.Duration) error {
 	for len(s.stars) < s.maxStars {
-		s.stars = append(s.stars, newStar(s.projAspect, s.f))
+		s.stars = append(s.stars, newStar(s.rand, s.projAspect, s.f))
 	}
 
+	stepsize := s.speed * depthPerSpeed * delta.Seconds()
+	driftX, driftY := s.driftX*delta.Seconds(), s.driftY*delta.Seconds()
+	decay := math.Pow(0.5, delta.Seconds()/steerHalfLife.Seconds())
+	s.driftX *= decay
+	s.driftY *= decay
 	next := []*star{}
 
 	for _, st := range s.stars {
@@ -171,11 +225,11 @@ func (s *StarfieldSaver) Update() error {
 		x := int((projected[0] + 1) * 0.5 * float64(s.width))
 		y := int((-projected[1] + 1) * 0.5 * float64(s.height))
 
-		if x > 0 && x < s.width && y > 0 && y < s.width {
-			drawStr(s.screen, x, y, style, c)
-			// TODO WAG
-			stepsize := s.speed * .04
+		if x > 0 && x < s.width && y > 0 && y < s.height {
+			s.canvas.DrawString(x, y, style, c)
 			st.Step(stepsize)
+			st.vec[0] += driftX
+			st.vec[1] += driftY
 			next = append(next, st)
 		}
 	}
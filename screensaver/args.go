@@ -0,0 +1,164 @@
+package screensaver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// parseSaverArgs reads the values for a saver's inputs out of
+// opts.SaverArgs, falling back to the defaults from the config file and
+// then the saver's own. Arguments the saver doesn't take are an error unless
+// opts.Lenient is set, in which case they are ignored.
+func parseSaverArgs(name string, inputs map[string]shared.SaverInput, opts shared.ScreensaverOpts) (shared.InputValues, error) {
+	inputs, err := withDefaults(name, inputs, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Usage = func() {}
+	fs.ParseErrorsWhitelist.UnknownFlags = opts.Lenient
+	for inputName, input := range inputs {
+		fs.String(inputName, input.Default, input.Description)
+		if input.Type == shared.InputBool {
+			// Let --flag mean --flag=true, as it would for any other bool.
+			fs.Lookup(inputName).NoOptDefVal = "true"
+		}
+	}
+
+	err = fs.Parse(argsFor(name, opts.SaverArgs, opts.Savers))
+	if errors.Is(err, pflag.ErrHelp) {
+		return nil, fmt.Errorf("run gh screensaver describe %s to see its inputs", baseName(name))
+	}
+	if err != nil {
+		msg := err.Error()
+		if strings.HasPrefix(msg, "unknown flag: --") {
+			return nil, unknownInputError(name, strings.TrimPrefix(msg, "unknown flag: --"), inputs, opts.Rotate > 0 || opts.Mosaic != "" || len(opts.Layers) > 0)
+		}
+		return nil, fmt.Errorf("could not parse input args for %s: %w", name, err)
+	}
+
+	raw := map[string]string{}
+	for inputName := range inputs {
+		raw[inputName], _ = fs.GetString(inputName)
+	}
+	return shared.ParseInputs(inputs, raw)
+}
+
+// withDefaults returns a copy of inputs with the defaults from the config
+// file in place of the saver's own.
+func withDefaults(name string, inputs map[string]shared.SaverInput, opts shared.ScreensaverOpts) (map[string]shared.SaverInput, error) {
+	out := map[string]shared.SaverInput{}
+	for n, input := range inputs {
+		out[n] = input
+	}
+	if input, ok := out["color"]; ok && opts.ColorMode != "" {
+		input.Default = opts.ColorMode
+		out["color"] = input
+	}
+	for n, value := range opts.InputDefaults[name] {
+		input, ok := out[n]
+		if !ok {
+			return nil, fmt.Errorf("config file: %w", unknownInputError(name, n, inputs, false))
+		}
+		input.Default = value
+		out[n] = input
+	}
+	return out, nil
+}
+
+func unknownInputError(name, flag string, inputs map[string]shared.SaverInput, several bool) error {
+	names := []string{}
+	for n := range inputs {
+		names = append(names, n)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s has no input --%s", name, flag)
+	if suggestions := suggest(flag, names); len(suggestions) > 0 {
+		fmt.Fprintf(&b, "; did you mean --%s?", strings.Join(suggestions, " or --"))
+	}
+	fmt.Fprintf(&b, "\nrun gh screensaver describe %s to see its inputs", baseName(name))
+	if several {
+		fmt.Fprintf(&b, "\nwith several screensavers, scope it to the screensavers that take it, as in --<saver>.%s,\nor pass --lenient to ignore inputs a screensaver doesn't take", flag)
+	}
+	return errors.New(b.String())
+}
+
+// suggest returns the candidates that are probably what a mistyped word was
+// meant to be: those within a couple of edits of it, or that start with it.
+func suggest(word string, candidates []string) []string {
+	type match struct {
+		name string
+		dist int
+	}
+	matches := []match{}
+	for _, c := range candidates {
+		d := editDistance(word, c)
+		if d <= 2 || (len(word) > 1 && strings.HasPrefix(c, word)) {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	out := []string{}
+	for _, m := range matches {
+		out = append(out, m.name)
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j] + 1
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if prev[j-1]+cost < cur[j] {
+				cur[j] = prev[j-1] + cost
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// checkSaverArgs parses the saver arguments for every saver in names, so
+// that mistakes are reported before the terminal is taken over rather than
+// when a rotation reaches the saver.
+func checkSaverArgs(opts shared.ScreensaverOpts, names []string) error {
+	for _, name := range names {
+		inputs, err := Inputs(opts.Savers[name])
+		if err != nil {
+			return err
+		}
+		if _, err := parseSaverArgs(name, inputs, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package screensaver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"font", "font-file", "message", "speed", "text-color"}
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"mesage", []string{"message"}},
+		{"speeed", []string{"speed"}},
+		{"fnt", []string{"font"}},
+		{"font-", []string{"font", "font-file"}},
+		{"text", []string{"text-color"}},
+		{"zzz", []string{}},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.word, candidates); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggest(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestParseSaverArgs(t *testing.T) {
+	inputs := map[string]shared.SaverInput{
+		"message": {Default: "hi"},
+		"speed":   {Default: "10", Type: shared.InputFloat},
+	}
+	savers := map[string]shared.RegisteredSaver{"marquee": {}, "life": {}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		lenient bool
+		want    shared.InputValues
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: shared.InputValues{"message": "hi", "speed": 10.0},
+		},
+		{
+			name: "scoped to another saver",
+			args: []string{"--speed=3", "--life.seed", "gun"},
+			want: shared.InputValues{"message": "hi", "speed": 3.0},
+		},
+		{
+			name:    "unknown input",
+			args:    []string{"--mesage=yo"},
+			wantErr: "marquee has no input --mesage; did you mean --message?",
+		},
+		{
+			name:    "unknown input, lenient",
+			args:    []string{"--mesage=yo", "--speed", "3"},
+			lenient: true,
+			want:    shared.InputValues{"message": "hi", "speed": 3.0},
+		},
+		{
+			name:    "bad value",
+			args:    []string{"--speed=fast"},
+			wantErr: `invalid value "fast" for --speed`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := shared.ScreensaverOpts{SaverArgs: tt.args, Savers: savers, Lenient: tt.lenient}
+			got, err := parseSaverArgs("marquee", inputs, opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
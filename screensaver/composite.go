@@ -0,0 +1,103 @@
+package screensaver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// useComposite replaces the savers in names with a single saver called name
+// that runs them all, so that the rest of Run needn't know the difference.
+// Their arguments are checked here, since the composite itself takes none;
+// create is given the original savers and arguments to make them with.
+func useComposite(opts *shared.ScreensaverOpts, name string, names []string, meta shared.SaverMetadata, create shared.SaverCreator) error {
+	checked := map[string]bool{}
+	for _, n := range names {
+		if checked[n] {
+			continue
+		}
+		checked[n] = true
+		if err := checkSaverArgs(*opts, []string{n}); err != nil {
+			return err
+		}
+	}
+
+	savers, args := opts.Savers, opts.SaverArgs
+	opts.Savers = map[string]shared.RegisteredSaver{}
+	for n, rs := range savers {
+		opts.Savers[n] = rs
+	}
+	opts.Savers[name] = shared.RegisteredSaver{
+		Create: func(copts shared.ScreensaverOpts) (shared.Screensaver, error) {
+			copts.Savers, copts.SaverArgs = savers, args
+			return create(copts)
+		},
+		Metadata: meta,
+	}
+	opts.Screensaver = name
+	opts.SaverArgs = nil
+	return nil
+}
+
+// part is one of the savers a composite runs. Each is updated at its own
+// rate, and what it last drew stays on its canvas in between.
+type part struct {
+	name   string
+	meta   shared.SaverMetadata
+	saver  shared.Screensaver
+	canvas *shared.Canvas
+	// elapsed is how long it has been since the saver was last updated, and
+	// stale is set when it needs drawing again whether or not it is due to
+	// be.
+	elapsed time.Duration
+	stale   bool
+}
+
+// newPart creates the saver name on canvas as the i'th part of a composite,
+// with a seed of its own derived from opts.Seed.
+func newPart(opts shared.ScreensaverOpts, name string, i int, canvas *shared.Canvas) (*part, error) {
+	rs := opts.Savers[name]
+	popts := opts
+	popts.Screensaver = name
+	popts.Seed = opts.Seed + int64(i) + 1
+	popts.Canvas = canvas
+	saver, err := newSaver(rs.Create, popts)
+	if err != nil {
+		return nil, err
+	}
+	return &part{name: name, meta: rs.Metadata, saver: saver, canvas: canvas, stale: true}, nil
+}
+
+// update clears and updates the saver if a frame of it is due, given that
+// delta has passed since the last call.
+func (p *part) update(delta time.Duration, fps int) error {
+	p.elapsed += delta
+	if p.elapsed < frameInterval(p.saver, fps) && !p.stale {
+		return nil
+	}
+	p.saver.Clear()
+	if err := p.saver.Update(p.elapsed); err != nil {
+		return fmt.Errorf("%s: %w", p.name, err)
+	}
+	p.elapsed, p.stale = 0, false
+	return nil
+}
+
+// fastest is the frame interval of the fastest of parts, so that none of
+// them is slowed down.
+func fastest(parts []*part, fps int) time.Duration {
+	var interval time.Duration
+	for _, p := range parts {
+		if i := frameInterval(p.saver, fps); interval == 0 || i < interval {
+			interval = i
+		}
+	}
+	return interval
+}
+
+func closeParts(parts []*part) {
+	for _, p := range parts {
+		closeSaver(p.saver)
+	}
+}
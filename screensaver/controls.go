@@ -0,0 +1,131 @@
+package screensaver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// control is something the user can ask for from the keyboard when
+// opts.Controls is set.
+type control int
+
+const (
+	ctlNone control = iota
+	ctlQuit
+	ctlPause
+	ctlNext
+	ctlPrev
+	ctlFaster
+	ctlSlower
+	ctlReseed
+	ctlHelp
+)
+
+// Speeds are changed by doubling or halving, within these bounds.
+const (
+	minSpeed = 1.0 / 8
+	maxSpeed = 8.0
+)
+
+var controlHelp = [][2]string{
+	{"space", "pause or resume"},
+	{"n, right", "next screensaver"},
+	{"p, left", "previous screensaver"},
+	{"+, up", "faster"},
+	{"-, down", "slower"},
+	{"r", "start over with a new seed"},
+	{"?", "show or hide this help"},
+	{"q, esc", "quit"},
+}
+
+// controlFor says what a key press asks for.
+func controlFor(ev *tcell.EventKey) control {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		return ctlQuit
+	case tcell.KeyRight:
+		return ctlNext
+	case tcell.KeyLeft:
+		return ctlPrev
+	case tcell.KeyUp:
+		return ctlFaster
+	case tcell.KeyDown:
+		return ctlSlower
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q', 'Q':
+			return ctlQuit
+		case ' ':
+			return ctlPause
+		case 'n':
+			return ctlNext
+		case 'p':
+			return ctlPrev
+		case '+', '=':
+			return ctlFaster
+		case '-', '_':
+			return ctlSlower
+		case 'r':
+			return ctlReseed
+		case '?':
+			return ctlHelp
+		}
+	}
+	return ctlNone
+}
+
+// drawHelp draws a box listing the controls in the middle of screen, with
+// status as its title.
+func drawHelp(screen tcell.Screen, status string) {
+	lines := []string{status, ""}
+	for _, h := range controlHelp {
+		lines = append(lines, fmt.Sprintf("%-9s %s", h[0], h[1]))
+	}
+	drawBox(screen, lines)
+}
+
+// drawBox draws lines in a reverse video box in the middle of screen. The
+// first line is the title.
+func drawBox(screen tcell.Screen, lines []string) {
+	inner := 0
+	for _, l := range lines {
+		if w := runewidth.StringWidth(l); w > inner {
+			inner = w
+		}
+	}
+	boxW, boxH := inner+4, len(lines)+2
+	width, height := screen.Size()
+	x0, y0 := (width-boxW)/2, (height-boxH)/2
+
+	style := tcell.StyleDefault.Reverse(true)
+	for y := 0; y < boxH; y++ {
+		for x := 0; x < boxW; x++ {
+			screen.SetContent(x0+x, y0+y, ' ', nil, style)
+		}
+	}
+	for i, l := range lines {
+		x := x0 + 2
+		if i == 0 {
+			x = x0 + (boxW-runewidth.StringWidth(l))/2
+		}
+		for _, r := range l {
+			screen.SetContent(x, y0+1+i, r, nil, style.Bold(i == 0))
+			x += runewidth.RuneWidth(r)
+		}
+	}
+}
+
+// controlStatus is the title of the help box.
+func controlStatus(name string, paused bool, speed float64) string {
+	parts := []string{name}
+	if paused {
+		parts = append(parts, "paused")
+	}
+	if speed != 1 {
+		parts = append(parts, fmt.Sprintf("speed %gx", speed))
+	}
+	return strings.Join(parts, " - ")
+}
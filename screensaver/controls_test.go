@@ -0,0 +1,30 @@
+package screensaver
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestControlFor(t *testing.T) {
+	tests := []struct {
+		ev   *tcell.EventKey
+		want control
+	}{
+		{tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), ctlQuit},
+		{tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone), ctlQuit},
+		{tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), ctlPause},
+		{tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone), ctlNext},
+		{tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone), ctlPrev},
+		{tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone), ctlFaster},
+		{tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), ctlSlower},
+		{tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone), ctlReseed},
+		{tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone), ctlHelp},
+		{tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), ctlNone},
+	}
+	for _, tt := range tests {
+		if got := controlFor(tt.ev); got != tt.want {
+			t.Errorf("controlFor(%s) = %d, want %d", tt.ev.Name(), got, tt.want)
+		}
+	}
+}
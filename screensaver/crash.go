@@ -0,0 +1,74 @@
+package screensaver
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// SaverPanic is the error returned when a saver panics. By the time it is
+// returned the terminal has been put back the way it was.
+type SaverPanic struct {
+	Saver string
+	// Frame is the frame being drawn, or 0 if the saver was being created.
+	Frame         int
+	Width, Height int
+	// Seed is the saver's own seed, which is what --seed needs to be to run
+	// it the same way again.
+	Seed  int64
+	Value interface{}
+	Stack []byte
+	// Report is the crash report that was written, if any.
+	Report string
+}
+
+func (p *SaverPanic) Error() string {
+	msg := fmt.Sprintf("screensaver %s crashed on frame %d at %dx%d with seed %d: %v",
+		p.Saver, p.Frame, p.Width, p.Height, p.Seed, p.Value)
+	if p.Report != "" {
+		msg += "\nA crash report was written to " + p.Report
+	}
+	return msg
+}
+
+// crashed turns a recovered panic into a *SaverPanic, and writes a crash
+// report if opts.CrashReport asks for one. It must be called from the
+// deferred function that recovered, so that the stack is the panic's.
+func crashed(value interface{}, opts shared.ScreensaverOpts, name string, frame, width, height int, seed int64) error {
+	p := &SaverPanic{
+		Saver:  name,
+		Frame:  frame,
+		Width:  width,
+		Height: height,
+		Seed:   seed,
+		Value:  value,
+		Stack:  debug.Stack(),
+	}
+	if opts.CrashReport == "" {
+		return p
+	}
+	if err := writeCrashReport(opts.CrashReport, p, opts); err != nil {
+		return fmt.Errorf("%w\nCould not write a crash report: %v", p, err)
+	}
+	p.Report = opts.CrashReport
+	return p
+}
+
+func writeCrashReport(path string, p *SaverPanic, opts shared.ScreensaverOpts) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "gh-screensaver crash report, %s\n\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "saver:     %s\n", p.Saver)
+	fmt.Fprintf(&b, "frame:     %d\n", p.Frame)
+	fmt.Fprintf(&b, "size:      %dx%d\n", p.Width, p.Height)
+	fmt.Fprintf(&b, "seed:      %d\n", p.Seed)
+	fmt.Fprintf(&b, "arguments: %s\n", strings.Join(opts.SaverArgs, " "))
+	fmt.Fprintf(&b, "fps:       %d\n", opts.FPS)
+	fmt.Fprintf(&b, "go:        %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "\npanic: %v\n\n%s", p.Value, p.Stack)
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
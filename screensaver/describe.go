@@ -0,0 +1,94 @@
+package screensaver
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// Inputs asks a saver what inputs it takes. Inputs is a method, so this
+// means creating one, which is done on a canvas that is never shown.
+func Inputs(rs shared.RegisteredSaver) (map[string]shared.SaverInput, error) {
+	saver, err := rs.Create(shared.ScreensaverOpts{
+		Canvas: shared.NewCanvas(80, 24),
+		Rand:   rand.New(rand.NewSource(0)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer closeSaver(saver)
+	return saver.Inputs(), nil
+}
+
+// Describe writes a saver's metadata and inputs to w, in the form gh
+// screensaver uses for describe and its help.
+func Describe(w io.Writer, name string, rs shared.RegisteredSaver) error {
+	inputs, err := Inputs(rs)
+	if err != nil {
+		return fmt.Errorf("could not describe %s: %w", name, err)
+	}
+
+	md := rs.Metadata
+	fmt.Fprintf(w, "%s\n", name)
+	if md.Description != "" {
+		fmt.Fprintf(w, "  %s\n", md.Description)
+	}
+	if md.Author != "" {
+		fmt.Fprintf(w, "  Author: %s\n", md.Author)
+	}
+	if md.MinWidth > 0 || md.MinHeight > 0 {
+		fmt.Fprintf(w, "  Minimum size: %dx%d\n", md.MinWidth, md.MinHeight)
+	}
+
+	names := []string{}
+	for n := range inputs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(w, "\n%s", describeInput(n, inputs[n]))
+	}
+	return nil
+}
+
+func describeInput(name string, input shared.SaverInput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  --%s %s\n", name, input.Type)
+	fmt.Fprintf(&b, "      %s", input.Description)
+	if input.Default != "" {
+		fmt.Fprintf(&b, " (default %q)", input.Default)
+	}
+	b.WriteString("\n")
+	if len(input.Values) > 0 {
+		b.WriteString(wrap("One of: "+strings.Join(input.Values, ", "), "      ", 72))
+	}
+	if input.Min != input.Max {
+		fmt.Fprintf(&b, "      Between %s and %s\n",
+			strconv.FormatFloat(input.Min, 'f', -1, 64), strconv.FormatFloat(input.Max, 'f', -1, 64))
+	}
+	return b.String()
+}
+
+// wrap breaks text into lines no longer than width, each starting with
+// indent.
+func wrap(text, indent string, width int) string {
+	var b strings.Builder
+	line := indent
+	for _, word := range strings.Fields(text) {
+		if line != indent && len(line)+1+len(word) > width {
+			b.WriteString(line + "\n")
+			line = indent
+		}
+		if line != indent {
+			line += " "
+		}
+		line += word
+	}
+	b.WriteString(line + "\n")
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package screensaver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ExitPolicies are the kinds of input that can end Run in a terminal:
+//
+//	key     any key, or with Controls just q and escape
+//	motion  as key, and also moving or clicking the mouse
+//	chord   only the key chord in ExitChord, so stray input is ignored
+var ExitPolicies = []string{"key", "motion", "chord"}
+
+// DefaultChord is the chord that quits under the chord policy unless another
+// is given.
+const DefaultChord = "ctrl+q"
+
+type exitPolicy struct {
+	kind     string
+	controls bool
+	chord    chord
+}
+
+func newExitPolicy(kind, chordSpec string, controls bool) (exitPolicy, error) {
+	p := exitPolicy{kind: kind, controls: controls}
+	switch kind {
+	case "key", "motion":
+	case "chord":
+		if chordSpec == "" {
+			chordSpec = DefaultChord
+		}
+		c, err := parseChord(chordSpec)
+		if err != nil {
+			return p, err
+		}
+		p.chord = c
+	default:
+		return p, fmt.Errorf("unknown exit policy '%s'; must be one of %s", kind, strings.Join(ExitPolicies, ", "))
+	}
+	return p, nil
+}
+
+// quitsOn reports whether ev should end the session. Under --lock nothing
+// does; the passphrase prompt decides instead.
+func (p exitPolicy) quitsOn(ev tcell.Event) bool {
+	if p.kind == "lock" {
+		return false
+	}
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		if p.kind == "chord" {
+			return p.chord.matches(ev)
+		}
+		return !p.controls || controlFor(ev) == ctlQuit
+	case *tcell.EventMouse:
+		return p.kind == "motion"
+	}
+	return false
+}
+
+// wantsMouse reports whether the policy needs mouse events reported.
+func (p exitPolicy) wantsMouse() bool {
+	return p.kind == "motion"
+}
+
+// chord is a key together with the modifiers that must be held with it.
+type chord struct {
+	key  tcell.Key
+	r    rune
+	mods tcell.ModMask
+}
+
+// parseChord reads chords like ctrl+q, alt+x or ctrl+f10.
+func parseChord(spec string) (chord, error) {
+	parts := strings.Split(spec, "+")
+	c := chord{}
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(mod)) {
+		case "ctrl":
+			c.mods |= tcell.ModCtrl
+		case "alt":
+			c.mods |= tcell.ModAlt
+		case "meta":
+			c.mods |= tcell.ModMeta
+		case "shift":
+			c.mods |= tcell.ModShift
+		default:
+			return c, fmt.Errorf("unknown modifier '%s' in chord '%s'; use ctrl, alt, meta or shift", mod, spec)
+		}
+	}
+
+	name := strings.TrimSpace(parts[len(parts)-1])
+	if runes := []rune(name); len(runes) == 1 {
+		r := runes[0]
+		lower := []rune(strings.ToLower(name))[0]
+		if c.mods&tcell.ModCtrl != 0 && lower >= 'a' && lower <= 'z' {
+			// Terminals send ctrl and a letter as a single control code.
+			c.key = tcell.KeyCtrlA + tcell.Key(lower-'a')
+			c.mods &^= tcell.ModCtrl
+			return c, nil
+		}
+		c.key, c.r = tcell.KeyRune, r
+		return c, nil
+	}
+	for k, n := range tcell.KeyNames {
+		if strings.EqualFold(n, name) {
+			c.key = k
+			return c, nil
+		}
+	}
+	return c, fmt.Errorf("unknown key '%s' in chord '%s'", name, spec)
+}
+
+func (c chord) matches(ev *tcell.EventKey) bool {
+	if ev.Key() != c.key || (c.key == tcell.KeyRune && ev.Rune() != c.r) {
+		return false
+	}
+	mods := ev.Modifiers()
+	if c.key == tcell.KeyRune || (c.key >= tcell.KeyCtrlA && c.key <= tcell.KeyCtrlZ) {
+		// Shift is already in the rune, and ctrl in the control code.
+		mods &^= tcell.ModShift | tcell.ModCtrl
+	}
+	return mods == c.mods
+}
@@ -0,0 +1,73 @@
+package screensaver
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestChord(t *testing.T) {
+	tests := []struct {
+		spec string
+		ev   *tcell.EventKey
+		want bool
+	}{
+		{"ctrl+q", tcell.NewEventKey(tcell.KeyRune, 17, tcell.ModNone), true},
+		{"ctrl+q", tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone), false},
+		{"ctrl+q", tcell.NewEventKey(tcell.KeyRune, 17, tcell.ModAlt), false},
+		{"alt+x", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), true},
+		{"alt+x", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), false},
+		{"Q", tcell.NewEventKey(tcell.KeyRune, 'Q', tcell.ModShift), true},
+		{"ctrl+f10", tcell.NewEventKey(tcell.KeyF10, 0, tcell.ModCtrl), true},
+		{"ctrl+f10", tcell.NewEventKey(tcell.KeyF10, 0, tcell.ModNone), false},
+		{"esc", tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), true},
+	}
+	for _, tt := range tests {
+		c, err := parseChord(tt.spec)
+		if err != nil {
+			t.Fatalf("parseChord(%q): %v", tt.spec, err)
+		}
+		if got := c.matches(tt.ev); got != tt.want {
+			t.Errorf("%s matches %s = %v, want %v", tt.spec, tt.ev.Name(), got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"hyper+q", "ctrl+nope"} {
+		if _, err := parseChord(spec); err == nil {
+			t.Errorf("parseChord(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestExitPolicy(t *testing.T) {
+	key := tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)
+	q := tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)
+	motion := tcell.NewEventMouse(3, 4, tcell.ButtonNone, tcell.ModNone)
+
+	tests := []struct {
+		kind     string
+		controls bool
+		ev       tcell.Event
+		want     bool
+	}{
+		{"key", false, key, true},
+		{"key", false, motion, false},
+		{"key", true, key, false},
+		{"key", true, q, true},
+		{"motion", false, motion, true},
+		{"motion", false, key, true},
+		{"chord", false, key, false},
+		{"chord", true, q, false},
+		{"chord", false, motion, false},
+		{"chord", false, tcell.NewEventKey(tcell.KeyCtrlQ, 17, tcell.ModCtrl), true},
+	}
+	for _, tt := range tests {
+		p, err := newExitPolicy(tt.kind, "", tt.controls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.quitsOn(tt.ev); got != tt.want {
+			t.Errorf("%s (controls %v) quits on %T = %v, want %v", tt.kind, tt.controls, tt.ev, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package screensaver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	// gifFace is the bitmap font cells are drawn with. Its glyphs are either
+	// fully on or off, so the only colors in a frame are the cells' own.
+	gifFace = basicfont.Face7x13
+
+	gifDefaultFg = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	gifDefaultBg = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// ExportGIF renders opts.Frames frames headlessly, rasterizes each cell with
+// gifFace, and writes them to path as an animated GIF whose palette is built
+// from the colors actually used. opts is filled in as it is for Run.
+func ExportGIF(ctx context.Context, opts shared.ScreensaverOpts, path string) error {
+	if err := prepare(&opts); err != nil {
+		return err
+	}
+	width, height, err := parseSize(opts.Size)
+	if err != nil {
+		return err
+	}
+
+	cellW := gifFace.Advance
+	cellH := gifFace.Height
+	bounds := image.Rect(0, 0, width*cellW, height*cellH)
+
+	// Frames are kept as cells, not pixels, and rasterized when they are
+	// needed: once to count the colors used, and again to encode them.
+	frames := [][]gifCell{}
+	delays := []int{}
+	err = renderHeadless(ctx, opts, width, height, func(screen tcell.SimulationScreen, _ int, delta time.Duration) error {
+		frames = append(frames, gifCells(screen))
+		delays = append(delays, int(delta/(10*time.Millisecond)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	counts := map[color.RGBA]int{}
+	img := image.NewRGBA(bounds)
+	for _, cells := range frames {
+		rasterize(img, cells, width, cellW, cellH)
+		countColors(counts, img)
+	}
+	pal := buildPalette(counts, 256)
+	out := &gif.GIF{
+		Config: image.Config{
+			ColorModel: pal,
+			Width:      bounds.Dx(),
+			Height:     bounds.Dy(),
+		},
+	}
+
+	// After the first frame, only the part of each frame that changed is
+	// stored; identical frames just extend how long the previous one shows.
+	lookup := map[color.RGBA]uint8{}
+	prev, frame := image.NewRGBA(bounds), img
+	for i, cells := range frames {
+		rasterize(frame, cells, width, cellW, cellH)
+		rect := bounds
+		if i > 0 {
+			rect = changedRect(prev, frame)
+			if rect.Empty() {
+				out.Delay[len(out.Delay)-1] += delays[i]
+				continue
+			}
+		}
+
+		paletted := image.NewPaletted(rect, pal)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				c := frame.RGBAAt(x, y)
+				ix, ok := lookup[c]
+				if !ok {
+					ix = uint8(pal.Index(c))
+					lookup[c] = ix
+				}
+				paletted.SetColorIndex(x, y, ix)
+			}
+		}
+		out.Image = append(out.Image, paletted)
+		out.Delay = append(out.Delay, delays[i])
+		out.Disposal = append(out.Disposal, gif.DisposalNone)
+		prev, frame = frame, prev
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	w := bufio.NewWriter(f)
+	if err := gif.EncodeAll(w, out); err != nil {
+		f.Close()
+		return fmt.Errorf("could not encode gif: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// gifCell is a cell of the screen as it is drawn in a GIF.
+type gifCell struct {
+	r      rune
+	fg, bg color.RGBA
+}
+
+// gifCells captures what every cell of screen looks like, row by row.
+func gifCells(screen tcell.Screen) []gifCell {
+	width, height := screen.Size()
+	cells := make([]gifCell, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mainc, _, style, _ := screen.GetContent(x, y)
+			fg, bg, attrs := style.Decompose()
+			fgc, bgc := rgba(fg, gifDefaultFg), rgba(bg, gifDefaultBg)
+			if attrs&tcell.AttrReverse != 0 {
+				fgc, bgc = bgc, fgc
+			}
+			cells = append(cells, gifCell{r: mainc, fg: fgc, bg: bgc})
+		}
+	}
+	return cells
+}
+
+// rasterize draws cells, width to a row, onto img as cellW x cellH blocks:
+// each cell's background filled in, with its rune drawn on top in its
+// foreground color.
+func rasterize(img *image.RGBA, cells []gifCell, width, cellW, cellH int) {
+	d := &font.Drawer{Dst: img, Face: gifFace}
+	for i, c := range cells {
+		x, y := i%width, i/width
+		cell := image.Rect(x*cellW, y*cellH, (x+1)*cellW, (y+1)*cellH)
+		draw.Draw(img, cell, image.NewUniform(c.bg), image.Point{}, draw.Src)
+		if c.r == 0 || c.r == ' ' {
+			continue
+		}
+		d.Src = image.NewUniform(c.fg)
+		d.Dot = fixed.P(x*cellW, y*cellH+gifFace.Ascent)
+		d.DrawString(string(c.r))
+	}
+}
+
+func rgba(c tcell.Color, def color.RGBA) color.RGBA {
+	if !c.Valid() {
+		return def
+	}
+	r, g, b := c.RGB()
+	return color.RGBA{uint8(r), uint8(g), uint8(b), 0xff}
+}
+
+// changedRect returns the smallest rectangle containing every pixel that
+// differs between a and b.
+func changedRect(a, b *image.RGBA) image.Rectangle {
+	changed := image.Rectangle{}
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if a.RGBAAt(x, y) != b.RGBAAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return changed
+}
+
+// countColors adds how many pixels of img are each color to counts.
+func countColors(counts map[color.RGBA]int, img *image.RGBA) {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			counts[img.RGBAAt(x, y)]++
+		}
+	}
+}
+
+// buildPalette returns the colors in counts if there are no more than max of
+// them, and otherwise reduces them to max colors by median cut, weighting
+// each by how many pixels it colors.
+func buildPalette(counts map[color.RGBA]int, max int) color.Palette {
+	colors := make([]weightedColor, 0, len(counts))
+	for c, n := range counts {
+		colors = append(colors, weightedColor{c, n})
+	}
+	// Map iteration order is random; sort so the same frames always produce
+	// the same palette.
+	sort.Slice(colors, func(i, j int) bool {
+		a, b := colors[i].c, colors[j].c
+		if a.R != b.R {
+			return a.R < b.R
+		}
+		if a.G != b.G {
+			return a.G < b.G
+		}
+		return a.B < b.B
+	})
+
+	boxes := [][]weightedColor{colors}
+	for len(boxes) < max {
+		// Split the box with the widest range of any channel.
+		widest, widestRange := -1, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			if _, r := widestChannel(box); r > widestRange {
+				widest, widestRange = i, r
+			}
+		}
+		if widest < 0 {
+			break
+		}
+
+		box := boxes[widest]
+		ch, _ := widestChannel(box)
+		sort.SliceStable(box, func(i, j int) bool {
+			return channel(box[i].c, ch) < channel(box[j].c, ch)
+		})
+		total := 0
+		for _, wc := range box {
+			total += wc.n
+		}
+		split, seen := 1, box[0].n
+		for split < len(box)-1 && seen+box[split].n <= total/2 {
+			seen += box[split].n
+			split++
+		}
+		boxes[widest] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	pal := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		var r, g, b, total int
+		for _, wc := range box {
+			r += int(wc.c.R) * wc.n
+			g += int(wc.c.G) * wc.n
+			b += int(wc.c.B) * wc.n
+			total += wc.n
+		}
+		pal = append(pal, color.RGBA{uint8(r / total), uint8(g / total), uint8(b / total), 0xff})
+	}
+	return pal
+}
+
+type weightedColor struct {
+	c color.RGBA
+	n int
+}
+
+// widestChannel returns which of R, G and B (0, 1, 2) varies the most within
+// box, and by how much.
+func widestChannel(box []weightedColor) (int, int) {
+	best, bestRange := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := 255, 0
+		for _, wc := range box {
+			v := int(channel(wc.c, ch))
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		if hi-lo > bestRange {
+			best, bestRange = ch, hi-lo
+		}
+	}
+	return best, bestRange
+}
+
+func channel(c color.RGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
@@ -0,0 +1,236 @@
+package screensaver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// runHeadless renders a saver on a simulated screen instead of a terminal and
+// writes what it drew to out.
+func runHeadless(ctx context.Context, opts shared.ScreensaverOpts, out io.Writer) error {
+	if opts.Format != "text" && opts.Format != "ansi" {
+		return fmt.Errorf("unknown format '%s'; must be text or ansi", opts.Format)
+	}
+
+	width, height, err := parseSize(opts.Size)
+	if err != nil {
+		return err
+	}
+
+	var rec *recorder
+	if opts.Record != "" {
+		rec, err = newRecorder(opts.Record, width, height)
+		if err != nil {
+			return err
+		}
+		defer rec.Close()
+	}
+
+	w := bufio.NewWriter(out)
+	var elapsed time.Duration
+	err = renderHeadless(ctx, opts, width, height, func(screen tcell.SimulationScreen, frame int, delta time.Duration) error {
+		elapsed += delta
+		if rec != nil {
+			rec.Frame(screen, elapsed)
+		}
+
+		if opts.AllFrames || frame == opts.Frames {
+			if opts.Format == "ansi" {
+				// Home the cursor and clear so that cat-ing the output to a
+				// terminal plays it back.
+				fmt.Fprint(w, "\x1b[H\x1b[2J")
+			} else if opts.AllFrames {
+				fmt.Fprintf(w, "--- frame %d ---\n", frame)
+			}
+			dumpScreen(w, screen, opts.Format == "ansi")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if rec != nil {
+		return rec.Close()
+	}
+	return nil
+}
+
+// renderHeadless runs the chosen saver on a width x height simulation screen
+// for opts.Frames frames, calling frame after each one is drawn with its
+// number (starting at 1) and how much time it covered. Every frame is given
+// exactly one frame interval of time, so a given seed and size always produce
+// the same frames.
+func renderHeadless(ctx context.Context, opts shared.ScreensaverOpts, width, height int, frame func(tcell.SimulationScreen, int, time.Duration) error) (err error) {
+	if opts.Screensaver == "" {
+		opts.Screensaver = pickRandom(opts, width, height)
+	}
+	registered, ok := opts.Savers[opts.Screensaver]
+	if !ok {
+		return fmt.Errorf("no such screensaver '%s'; run gh screensaver -l to see choices", opts.Screensaver)
+	}
+	meta := registered.Metadata
+	if width < meta.MinWidth || height < meta.MinHeight {
+		return tooSmallError(opts.Screensaver, meta.MinWidth, meta.MinHeight, width, height)
+	}
+
+	if opts.Frames < 1 {
+		return fmt.Errorf("--frames must be at least 1, got %d", opts.Frames)
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+
+	opts.Style = tcell.StyleDefault
+	screen.SetStyle(opts.Style)
+	canvas := shared.NewCanvas(width, height)
+	opts.Canvas = canvas
+
+	n := 0
+	defer func() {
+		if r := recover(); r != nil {
+			err = crashed(r, opts, opts.Screensaver, n, width, height, opts.Seed)
+		}
+	}()
+
+	saver, err := newSaver(registered.Create, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSaver(saver)
+	if minW, minH := minSize(saver, meta); width < minW || height < minH {
+		return tooSmallError(opts.Screensaver, minW, minH, width, height)
+	}
+
+	for n = 1; n <= opts.Frames; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		delta := frameInterval(saver, opts.FPS)
+		saver.Clear()
+		if err := saver.Update(delta); err != nil {
+			return err
+		}
+		blit(screen, canvas)
+		screen.Show()
+		if err := frame(screen, n, delta); err != nil {
+			return err
+		}
+	}
+	if opts.Debug {
+		fmt.Fprintln(os.Stderr, boundsNote(opts.Screensaver, canvas))
+	}
+
+	return nil
+}
+
+// parseSize parses a WIDTHxHEIGHT string like 80x24.
+func parseSize(size string) (int, int, error) {
+	parts := strings.Split(strings.ToLower(size), "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("could not understand size '%s'; expected something like 80x24", size)
+	}
+	width, err := strconv.Atoi(parts[0])
+	if err != nil || width < 1 {
+		return 0, 0, fmt.Errorf("could not understand width in size '%s'", size)
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil || height < 1 {
+		return 0, 0, fmt.Errorf("could not understand height in size '%s'", size)
+	}
+	return width, height, nil
+}
+
+// dumpScreen writes the screen's contents a row at a time. In plain text mode
+// trailing blanks are trimmed; with ansi set, styles are written as SGR
+// escape sequences.
+func dumpScreen(w io.Writer, screen tcell.Screen, ansi bool) {
+	width, height := screen.Size()
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		current := tcell.StyleDefault
+		for x := 0; x < width; {
+			mainc, combc, style, cw := screen.GetContent(x, y)
+			if mainc == 0 {
+				mainc = ' '
+			}
+			if ansi && style != current {
+				line.WriteString(sgr(style))
+				current = style
+			}
+			line.WriteRune(mainc)
+			for _, c := range combc {
+				line.WriteRune(c)
+			}
+			if cw < 1 {
+				cw = 1
+			}
+			x += cw
+		}
+		if ansi {
+			fmt.Fprintf(w, "%s\x1b[0m\n", line.String())
+		} else {
+			fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+		}
+	}
+}
+
+// sgr returns the escape sequence that switches a terminal to style.
+func sgr(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	codes := []string{"0"}
+	if attrs&tcell.AttrBold != 0 {
+		codes = append(codes, "1")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		codes = append(codes, "2")
+	}
+	if attrs&tcell.AttrItalic != 0 {
+		codes = append(codes, "3")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		codes = append(codes, "4")
+	}
+	if attrs&tcell.AttrBlink != 0 {
+		codes = append(codes, "5")
+	}
+	if attrs&tcell.AttrReverse != 0 {
+		codes = append(codes, "7")
+	}
+	if code := sgrColor(fg); code != "" {
+		codes = append(codes, "38;"+code)
+	}
+	if code := sgrColor(bg); code != "" {
+		codes = append(codes, "48;"+code)
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// sgrColor returns the part of an SGR color parameter after 38; or 48;, or ""
+// for the terminal's default color.
+func sgrColor(c tcell.Color) string {
+	if !c.Valid() {
+		return ""
+	}
+	if !c.IsRGB() && c-tcell.ColorValid < 256 {
+		return fmt.Sprintf("5;%d", c-tcell.ColorValid)
+	}
+	r, g, b := c.RGB()
+	return fmt.Sprintf("2;%d;%d;%d", r, g, b)
+}
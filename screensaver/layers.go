@@ -0,0 +1,141 @@
+package screensaver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// layersName is what layered savers run as, in place of a saver's name.
+const layersName = "layers"
+
+// useLayers replaces the savers opts.Layers asks for with a single saver that
+// draws them one over the other.
+func useLayers(opts *shared.ScreensaverOpts) error {
+	meta := shared.SaverMetadata{}
+	for _, name := range opts.Layers {
+		rs, ok := opts.Savers[name]
+		if !ok {
+			return fmt.Errorf("no such screensaver '%s' in --layers; run gh screensaver -l to see choices", name)
+		}
+		if rs.Metadata.MinWidth > meta.MinWidth {
+			meta.MinWidth = rs.Metadata.MinWidth
+		}
+		if rs.Metadata.MinHeight > meta.MinHeight {
+			meta.MinHeight = rs.Metadata.MinHeight
+		}
+	}
+	meta.Description = fmt.Sprintf("%s, one over the other.", strings.Join(opts.Layers, ", "))
+
+	names := opts.Layers
+	return useComposite(opts, layersName, names, meta, func(lopts shared.ScreensaverOpts) (shared.Screensaver, error) {
+		return newLayered(lopts, names)
+	})
+}
+
+// layered runs several savers on the whole screen at once, each drawing on a
+// canvas of its own. Every frame the canvases are put together in order, the
+// first at the bottom, with the cells a saver left unset showing what is
+// below.
+type layered struct {
+	canvas *shared.Canvas
+	fps    int
+	layers []*part
+}
+
+// newLayered creates the savers in names, the first being the bottom layer,
+// each on a canvas the size of opts.Canvas.
+func newLayered(opts shared.ScreensaverOpts, names []string) (*layered, error) {
+	l := &layered{canvas: opts.Canvas, fps: opts.FPS}
+	width, height := l.canvas.Size()
+	for i, name := range names {
+		canvas := shared.NewCanvas(atLeast(opts.Savers[name].Metadata, width, height))
+		p, err := newPart(opts, name, i, canvas)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l.layers = append(l.layers, p)
+	}
+	return l, nil
+}
+
+func (l *layered) Initialize(opts shared.ScreensaverOpts) error {
+	return nil
+}
+
+func (l *layered) Inputs() map[string]shared.SaverInput {
+	return map[string]shared.SaverInput{}
+}
+
+func (l *layered) SetInputs(inputs shared.InputValues) error {
+	return nil
+}
+
+// MinSize is the smallest screen every layer fits on.
+func (l *layered) MinSize() (int, int) {
+	width, height := 0, 0
+	for _, p := range l.layers {
+		minW, minH := minSize(p.saver, p.meta)
+		if minW > width {
+			width = minW
+		}
+		if minH > height {
+			height = minH
+		}
+	}
+	return width, height
+}
+
+// Clear does nothing, since Update puts the canvas together afresh from the
+// layers every frame.
+func (l *layered) Clear() {}
+
+func (l *layered) Update(delta time.Duration) error {
+	width, height := l.canvas.Size()
+	for _, p := range l.layers {
+		if fits, _, _ := fitSaver(p.saver, p.meta, p.canvas, width, height); !fits {
+			continue
+		}
+		if err := p.update(delta, l.fps); err != nil {
+			return err
+		}
+	}
+
+	l.canvas.Clear()
+	for _, p := range l.layers {
+		composite(l.canvas, p.canvas)
+	}
+	return nil
+}
+
+// composite draws the cells that are set in src over dst.
+func composite(dst, src *shared.Canvas) {
+	width, height := dst.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if cell := src.Cell(x, y); cell.Set() {
+				dst.SetCell(x, y, cell)
+			}
+		}
+	}
+}
+
+func (l *layered) Interval() time.Duration {
+	return fastest(l.layers, l.fps)
+}
+
+// Resize has every layer drawn again. They are given the new size as they
+// are updated.
+func (l *layered) Resize(width, height int) {
+	for _, p := range l.layers {
+		p.stale = true
+	}
+}
+
+func (l *layered) Close() error {
+	closeParts(l.layers)
+	return nil
+}
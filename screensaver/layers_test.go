@@ -0,0 +1,103 @@
+package screensaver
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// gapSaver fills every other cell of the top row.
+type gapSaver struct {
+	dotSaver
+}
+
+func (g *gapSaver) Update(delta time.Duration) error {
+	width, _ := g.canvas.Size()
+	for x := 0; x < width; x += 2 {
+		g.canvas.SetContent(x, 0, '-', nil, tcell.StyleDefault)
+	}
+	return nil
+}
+
+func TestLayers(t *testing.T) {
+	savers := map[string]shared.RegisteredSaver{
+		"dot": {Create: newDotSaver},
+		"gaps": {Create: func(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+			g := &gapSaver{}
+			return g, g.Initialize(opts)
+		}},
+	}
+	tests := []struct {
+		layers []string
+		want   string
+	}{
+		// Whichever is on top wins where both drew, and the gaps show around
+		// the dot.
+		{[]string{"gaps", "dot"}, "- . - -\n"},
+		{[]string{"dot", "gaps"}, "- - - -\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := Run(context.Background(), shared.ScreensaverOpts{
+			Layers:   tt.layers,
+			Savers:   savers,
+			Headless: true,
+			Frames:   3,
+			Size:     "7x1",
+			Out:      &out,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("layers %v: got %q, want %q", tt.layers, got, tt.want)
+		}
+	}
+}
+
+// fillSaver fills its whole canvas.
+type fillSaver struct {
+	dotSaver
+}
+
+func (f *fillSaver) Update(delta time.Duration) error {
+	width, height := f.canvas.Size()
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			f.canvas.SetContent(x, y, '+', nil, tcell.StyleDefault)
+		}
+	}
+	return nil
+}
+
+func TestLayersShowThroughRealSavers(t *testing.T) {
+	savers := Savers()
+	savers["fill"] = shared.RegisteredSaver{Create: func(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+		f := &fillSaver{}
+		return f, f.Initialize(opts)
+	}}
+	// With this seed, the 25th frame catches a firework bursting.
+	for _, top := range []string{"life", "fireworks"} {
+		var out bytes.Buffer
+		err := Run(context.Background(), shared.ScreensaverOpts{
+			Layers:   []string{"fill", top},
+			Savers:   savers,
+			Headless: true,
+			Frames:   25,
+			Size:     "40x20",
+			Seed:     1,
+			Out:      &out,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); strings.Contains(got, " ") || !strings.Contains(got, "+") {
+			t.Errorf("%s hides the layer below it:\n%s", top, got)
+		}
+	}
+}
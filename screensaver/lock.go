@@ -0,0 +1,141 @@
+package screensaver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// promptTimeout is how long the passphrase prompt waits for the next key
+	// before going away and forgetting what was typed.
+	promptTimeout = 30 * time.Second
+	// Each wrong passphrase doubles the wait before the next try, starting
+	// from firstRetryDelay, up to maxRetryDelay.
+	firstRetryDelay = time.Second
+	maxRetryDelay   = 5 * time.Minute
+	maxPassphrase   = 1024
+)
+
+// checkLockHash makes sure hash is a bcrypt hash, so that a passphrase put in
+// the config file as is doesn't make for a lock nobody can open.
+func checkLockHash(hash string) error {
+	if hash == "" {
+		return errors.New("--lock needs a passphrase; put a bcrypt hash of one in the config file, made with gh screensaver passphrase")
+	}
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return fmt.Errorf("the lock passphrase must be a bcrypt hash, made with gh screensaver passphrase: %w", err)
+	}
+	return nil
+}
+
+// lock asks for the passphrase whose bcrypt hash it holds. Any key shows the
+// prompt, and the session ends once the passphrase is right.
+type lock struct {
+	hash      []byte
+	prompting bool
+	typed     []rune
+	lastKey   time.Time
+	failures  int
+	retryAt   time.Time
+}
+
+func newLock(hash string) *lock {
+	return &lock{hash: []byte(hash)}
+}
+
+// handle takes a key press and reports whether it unlocked the session.
+func (l *lock) handle(ev *tcell.EventKey, now time.Time) bool {
+	l.expire(now)
+	l.lastKey = now
+	if !l.prompting {
+		l.prompting = true
+		return false
+	}
+	if now.Before(l.retryAt) {
+		return false
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		ok := bcrypt.CompareHashAndPassword(l.hash, []byte(string(l.typed))) == nil
+		l.typed = nil
+		if ok {
+			return true
+		}
+		delay := firstRetryDelay << l.failures
+		if delay > maxRetryDelay || delay <= 0 {
+			delay = maxRetryDelay
+		}
+		l.failures++
+		// Timed from after the check, since bcrypt itself takes a while.
+		l.retryAt = time.Now().Add(delay)
+	case tcell.KeyEscape:
+		l.prompting = false
+		l.typed = nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(l.typed) > 0 {
+			l.typed = l.typed[:len(l.typed)-1]
+		}
+	case tcell.KeyCtrlU:
+		l.typed = nil
+	case tcell.KeyRune:
+		if len(l.typed) < maxPassphrase {
+			l.typed = append(l.typed, ev.Rune())
+		}
+	}
+	return false
+}
+
+// expire puts the prompt away if it has been left alone for too long.
+func (l *lock) expire(now time.Time) {
+	if l.prompting && now.Sub(l.lastKey) > promptTimeout && !now.Before(l.retryAt) {
+		l.prompting = false
+		l.typed = nil
+	}
+}
+
+// draw shows the prompt, if it is up, in the middle of screen.
+func (l *lock) draw(screen tcell.Screen, now time.Time) {
+	l.expire(now)
+	if !l.prompting {
+		return
+	}
+
+	status := "enter to unlock, esc to cancel"
+	if wait := l.retryAt.Sub(now); wait > 0 {
+		status = fmt.Sprintf("wrong passphrase; try again in %ds", int(wait.Seconds())+1)
+	}
+	stars := len(l.typed)
+	if stars > 24 {
+		stars = 24
+	}
+	// The passphrase line is padded so the box doesn't grow while typing.
+	drawBox(screen, []string{
+		"locked",
+		"",
+		fmt.Sprintf("%-37s", "passphrase: "+strings.Repeat("*", stars)+"_"),
+		"",
+		status,
+	})
+}
+
+// blank is what --lock shows in place of a saver that failed, so that the
+// session stays locked until the passphrase is typed.
+type blank struct{}
+
+func (blank) Initialize(opts shared.ScreensaverOpts) error { return nil }
+func (blank) SetInputs(shared.InputValues) error           { return nil }
+func (blank) Inputs() map[string]shared.SaverInput         { return nil }
+func (blank) Clear()                                       {}
+func (blank) Update(delta time.Duration) error             { return nil }
+func (blank) Interval() time.Duration                      { return shared.DefaultInterval }
+func (blank) Resize(width, height int)                     {}
+
+// MinSize is zero, since there is nothing to fit.
+func (blank) MinSize() (int, int) { return 0, 0 }
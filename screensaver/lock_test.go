@@ -0,0 +1,67 @@
+package screensaver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func typeInto(l *lock, s string, now time.Time) bool {
+	for _, r := range s {
+		if l.handle(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), now) {
+			return true
+		}
+	}
+	return l.handle(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), now)
+}
+
+func TestLock(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("sesame"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkLockHash(string(hash)); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkLockHash("sesame"); err == nil {
+		t.Error("checkLockHash accepted a passphrase that isn't hashed")
+	}
+
+	l := newLock(string(hash))
+	now := time.Now()
+
+	// The first key only brings up the prompt.
+	if typeInto(l, "sesame", now) {
+		t.Fatal("unlocked by a key typed before the prompt was up")
+	}
+	l = newLock(string(hash))
+	l.handle(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), now)
+	if !l.prompting {
+		t.Fatal("a key didn't bring up the prompt")
+	}
+
+	if typeInto(l, "open", now) {
+		t.Fatal("unlocked with the wrong passphrase")
+	}
+	if !now.Before(l.retryAt) {
+		t.Fatal("no wait after a wrong passphrase")
+	}
+	if typeInto(l, "sesame", now) {
+		t.Fatal("unlocked while waiting to retry")
+	}
+
+	later := l.retryAt.Add(time.Millisecond)
+	if !typeInto(l, "sesame", later) {
+		t.Error("the right passphrase didn't unlock")
+	}
+
+	l = newLock(string(hash))
+	l.handle(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), now)
+	l.handle(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone), now)
+	l.expire(now.Add(promptTimeout + time.Second))
+	if l.prompting || len(l.typed) > 0 {
+		t.Error("the prompt didn't go away after being left alone")
+	}
+}
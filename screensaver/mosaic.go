@@ -0,0 +1,229 @@
+package screensaver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// mosaicName is what a mosaic runs as, in place of a saver's name.
+const mosaicName = "mosaic"
+
+var mosaicGrid = regexp.MustCompile(`^(\d+)x(\d+)$`)
+
+// useMosaic replaces the savers opts.Mosaic asks for with a single saver that
+// tiles them.
+func useMosaic(opts *shared.ScreensaverOpts) error {
+	width, height := terminalSize()
+	if opts.Headless {
+		var err error
+		if width, height, err = parseSize(opts.Size); err != nil {
+			return err
+		}
+	}
+	names, cols, rows, err := mosaicSavers(*opts, width, height)
+	if err != nil {
+		return err
+	}
+	return useComposite(opts, mosaicName, names,
+		shared.SaverMetadata{Description: "Several screensavers side by side."},
+		func(mopts shared.ScreensaverOpts) (shared.Screensaver, error) {
+			return newMosaic(mopts, names, cols, rows)
+		})
+}
+
+// mosaicSavers works out which savers opts.Mosaic tiles, and in how many
+// columns and rows. A COLSxROWS mosaic is filled with savers picked at random
+// from those that fit its tiles on a width x height screen, using each once
+// before using any twice. A list of savers is laid out as near to square as
+// it will go.
+func mosaicSavers(opts shared.ScreensaverOpts, width, height int) (names []string, cols, rows int, err error) {
+	if m := mosaicGrid.FindStringSubmatch(opts.Mosaic); m != nil {
+		cols, _ = strconv.Atoi(m[1])
+		rows, _ = strconv.Atoi(m[2])
+		if cols < 1 || rows < 1 {
+			return nil, 0, 0, fmt.Errorf("--mosaic needs at least one column and one row, got %s", opts.Mosaic)
+		}
+		tile := mosaicLayout(cols, rows, width, height, opts.Labels)[0]
+		candidates := []string{}
+		for _, name := range Names(opts.Savers) {
+			meta := opts.Savers[name].Metadata
+			if width == 0 || (tile.w >= meta.MinWidth && tile.h >= meta.MinHeight) {
+				candidates = append(candidates, name)
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = Names(opts.Savers)
+		}
+		r := rand.New(rand.NewSource(opts.Seed))
+		for len(names) < cols*rows {
+			for _, i := range r.Perm(len(candidates)) {
+				names = append(names, candidates[i])
+			}
+		}
+		return names[:cols*rows], cols, rows, nil
+	}
+
+	for _, name := range strings.Split(opts.Mosaic, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := opts.Savers[name]; !ok {
+			return nil, 0, 0, fmt.Errorf("no such screensaver '%s' in --mosaic; run gh screensaver -l to see choices", name)
+		}
+		names = append(names, name)
+	}
+	cols = int(math.Ceil(math.Sqrt(float64(len(names)))))
+	rows = (len(names) + cols - 1) / cols
+	return names, cols, rows, nil
+}
+
+// rect is a region of the screen.
+type rect struct {
+	x, y, w, h int
+}
+
+// mosaicLayout splits a width x height screen into cols x rows tiles, given
+// row by row, with a blank column between tiles. Rows of tiles are separated
+// by a blank row, or with labels by the row the labels go in.
+func mosaicLayout(cols, rows, width, height int, labels bool) []rect {
+	gap := 1
+	if labels {
+		gap = 0
+	}
+	tiles := []rect{}
+	for row := 0; row < rows; row++ {
+		top := row * (height + gap) / rows
+		bottom := (row+1)*(height+gap)/rows - gap
+		if labels {
+			top++
+		}
+		for col := 0; col < cols; col++ {
+			left := col * (width + 1) / cols
+			right := (col+1)*(width+1)/cols - 1
+			tiles = append(tiles, rect{x: left, y: top, w: right - left, h: bottom - top})
+		}
+	}
+	return tiles
+}
+
+// tile is one of the savers in a mosaic, drawing on a view of the mosaic's
+// canvas.
+type tile struct {
+	*part
+	region rect
+}
+
+// mosaic runs several savers at once, each in its own part of the canvas. A
+// saver whose tile is too small for it says so in its tile, and waits there
+// until the terminal is big enough.
+type mosaic struct {
+	canvas     *shared.Canvas
+	style      tcell.Style
+	cols, rows int
+	labels     bool
+	fps        int
+	tiles      []*tile
+}
+
+// newMosaic creates the savers in names and lays them out cols x rows on
+// opts.Canvas.
+func newMosaic(opts shared.ScreensaverOpts, names []string, cols, rows int) (*mosaic, error) {
+	m := &mosaic{
+		canvas: opts.Canvas,
+		style:  opts.Style,
+		cols:   cols,
+		rows:   rows,
+		labels: opts.Labels,
+		fps:    opts.FPS,
+	}
+	width, height := m.canvas.Size()
+	regions := mosaicLayout(cols, rows, width, height, m.labels)
+	for i, name := range names {
+		r := regions[i]
+		// Like any other saver, a tile's saver is created no smaller than it
+		// allows, and waits for a region that fits before it is updated.
+		view := m.canvas.Sub(r.x, r.y, r.w, r.h)
+		view.SetSize(atLeast(opts.Savers[name].Metadata, r.w, r.h))
+		p, err := newPart(opts, name, i, view)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m.tiles = append(m.tiles, &tile{part: p, region: r})
+	}
+	return m, nil
+}
+
+func (m *mosaic) Initialize(opts shared.ScreensaverOpts) error {
+	return nil
+}
+
+func (m *mosaic) Inputs() map[string]shared.SaverInput {
+	return map[string]shared.SaverInput{}
+}
+
+func (m *mosaic) SetInputs(inputs shared.InputValues) error {
+	return nil
+}
+
+// Clear leaves the canvas alone. A tile that isn't due for a frame has to keep
+// showing its last one, so each is cleared by its own update instead.
+func (m *mosaic) Clear() {}
+
+func (m *mosaic) Update(delta time.Duration) error {
+	for _, t := range m.tiles {
+		r := t.region
+		if m.labels && r.w > 0 {
+			label := runewidth.Truncate(t.name, r.w, "")
+			label += strings.Repeat(" ", r.w-runewidth.StringWidth(label))
+			m.canvas.Sub(r.x, r.y-1, r.w, 1).DrawString(0, 0, m.style.Reverse(true), label)
+		}
+		fits, minW, minH := fitSaver(t.saver, t.meta, t.canvas, r.w, r.h)
+		if !fits {
+			region := m.canvas.Sub(r.x, r.y, r.w, r.h)
+			region.Clear()
+			drawTooSmall(region, "tile", minW, minH)
+			t.stale = true
+			continue
+		}
+		if err := t.update(delta, m.fps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mosaic) Interval() time.Duration {
+	return fastest(m.parts(), m.fps)
+}
+
+// Resize lays the tiles out again. Each is given its new size once it fits.
+func (m *mosaic) Resize(width, height int) {
+	m.canvas.Clear()
+	regions := mosaicLayout(m.cols, m.rows, width, height, m.labels)
+	for i, t := range m.tiles {
+		t.region = regions[i]
+		t.canvas.Move(t.region.x, t.region.y)
+		t.stale = true
+	}
+}
+
+func (m *mosaic) Close() error {
+	closeParts(m.parts())
+	return nil
+}
+
+func (m *mosaic) parts() []*part {
+	parts := []*part{}
+	for _, t := range m.tiles {
+		parts = append(parts, t.part)
+	}
+	return parts
+}
@@ -0,0 +1,89 @@
+package screensaver
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+func TestMosaic(t *testing.T) {
+	var out bytes.Buffer
+	err := Run(context.Background(), shared.ScreensaverOpts{
+		Mosaic: "dot,dot,big,dot",
+		Savers: map[string]shared.RegisteredSaver{
+			"dot": {Create: newDotSaver},
+			"big": {Create: newDotSaver, Metadata: shared.SaverMetadata{MinWidth: 10, MinHeight: 10}},
+		},
+		Headless: true,
+		Frames:   2,
+		Size:     "9x3",
+		Out:      &out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each dot is in its own tile, and the tile too small for big says so
+	// as much as fits.
+	want := " .    .\n\nneed  .\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMosaicLayout(t *testing.T) {
+	tests := []struct {
+		cols, rows    int
+		width, height int
+		labels        bool
+		want          []rect
+	}{
+		{2, 1, 9, 4, false, []rect{{0, 0, 4, 4}, {5, 0, 4, 4}}},
+		{1, 2, 4, 9, false, []rect{{0, 0, 4, 4}, {0, 5, 4, 4}}},
+		{1, 2, 4, 10, true, []rect{{0, 1, 4, 4}, {0, 6, 4, 4}}},
+		// Whatever is left over is spread between the tiles.
+		{3, 1, 12, 1, false, []rect{{0, 0, 3, 1}, {4, 0, 3, 1}, {8, 0, 4, 1}}},
+	}
+	for _, tt := range tests {
+		got := mosaicLayout(tt.cols, tt.rows, tt.width, tt.height, tt.labels)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mosaicLayout(%d, %d, %d, %d, %v) = %v, want %v",
+				tt.cols, tt.rows, tt.width, tt.height, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestMosaicSavers(t *testing.T) {
+	savers := map[string]shared.RegisteredSaver{
+		"a": {Create: newDotSaver},
+		"b": {Create: newDotSaver},
+		"c": {Create: newDotSaver, Metadata: shared.SaverMetadata{MinWidth: 50}},
+	}
+	names, cols, rows, err := mosaicSavers(shared.ScreensaverOpts{Mosaic: "2x2", Savers: savers}, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols != 2 || rows != 2 || len(names) != 4 {
+		t.Fatalf("got %v in %dx%d, want 4 savers in 2x2", names, cols, rows)
+	}
+	// c doesn't fit a tile, and each of the others is used before either is
+	// used again.
+	if names[0] == names[1] || names[2] == names[3] {
+		t.Errorf("got %v, want a and b twice over", names)
+	}
+	for _, name := range names {
+		if name == "c" {
+			t.Errorf("got %v, want no c since it doesn't fit", names)
+		}
+	}
+
+	_, cols, rows, err = mosaicSavers(shared.ScreensaverOpts{Mosaic: "a,b,c,a,b", Savers: savers}, 0, 0)
+	if err != nil || cols != 3 || rows != 2 {
+		t.Errorf("got %dx%d, %v, want 3x2", cols, rows, err)
+	}
+	if _, _, _, err := mosaicSavers(shared.ScreensaverOpts{Mosaic: "a,nope", Savers: savers}, 0, 0); err == nil {
+		t.Error("got no error for a saver that doesn't exist")
+	}
+}
@@ -0,0 +1,155 @@
+package screensaver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// recorder writes frames to an asciicast v2 file
+// (https://docs.asciinema.org/manual/asciicast/v2/) so that a session can be
+// played back with asciinema. Only the cells that changed since the previous
+// frame are written.
+type recorder struct {
+	f      *os.File
+	w      *bufio.Writer
+	width  int
+	height int
+	prev   []recordedCell
+	err    error
+	closed bool
+}
+
+type recordedCell struct {
+	text  string
+	style tcell.Style
+}
+
+func newRecorder(path string, width, height int) (*recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create recording: %w", err)
+	}
+
+	r := &recorder{
+		f: f,
+		w: bufio.NewWriter(f),
+	}
+	r.setSize(width, height)
+
+	header := map[string]interface{}{
+		"version":   2,
+		"width":     width,
+		"height":    height,
+		"timestamp": time.Now().Unix(),
+		"env": map[string]string{
+			"TERM": os.Getenv("TERM"),
+		},
+	}
+	r.writeLine(header)
+	// Hide the cursor and start from a blank screen.
+	r.event(0, "o", "\x1b[?25l\x1b[0m\x1b[2J")
+
+	return r, r.err
+}
+
+func (r *recorder) setSize(width, height int) {
+	r.width, r.height = width, height
+	r.prev = make([]recordedCell, width*height)
+	for i := range r.prev {
+		r.prev[i] = recordedCell{text: " ", style: tcell.StyleDefault}
+	}
+}
+
+// Frame records what is on screen at elapsed time since the recording
+// started.
+func (r *recorder) Frame(screen tcell.Screen, elapsed time.Duration) {
+	if r.err != nil {
+		return
+	}
+
+	var out strings.Builder
+
+	width, height := screen.Size()
+	if width != r.width || height != r.height {
+		r.event(elapsed, "r", fmt.Sprintf("%dx%d", width, height))
+		r.setSize(width, height)
+		out.WriteString("\x1b[0m\x1b[2J")
+	}
+
+	var current tcell.Style
+	styled := false
+	cursorX, cursorY := -1, -1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; {
+			mainc, combc, style, cw := screen.GetContent(x, y)
+			if mainc == 0 {
+				mainc = ' '
+			}
+			if cw < 1 {
+				cw = 1
+			}
+			cell := recordedCell{text: string(append([]rune{mainc}, combc...)), style: style}
+			if r.prev[y*width+x] == cell {
+				x += cw
+				continue
+			}
+			r.prev[y*width+x] = cell
+
+			if x != cursorX || y != cursorY {
+				fmt.Fprintf(&out, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if !styled || style != current {
+				out.WriteString(sgr(style))
+				current, styled = style, true
+			}
+			out.WriteString(cell.text)
+			cursorX, cursorY = x+cw, y
+			x += cw
+		}
+	}
+
+	if out.Len() > 0 {
+		r.event(elapsed, "o", out.String())
+	}
+}
+
+func (r *recorder) event(elapsed time.Duration, kind, data string) {
+	r.writeLine([]interface{}{elapsed.Seconds(), kind, data})
+}
+
+func (r *recorder) writeLine(v interface{}) {
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return
+	}
+	line = append(line, '\n')
+	if _, err := r.w.Write(line); err != nil {
+		r.err = fmt.Errorf("could not write recording: %w", err)
+	}
+}
+
+// Close flushes the recording to disk and reports the first error, if any,
+// that happened while recording. It is safe to call more than once.
+func (r *recorder) Close() error {
+	if r.closed {
+		return r.err
+	}
+	r.closed = true
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if err := r.f.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
@@ -0,0 +1,102 @@
+package screensaver
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// rotation is the order savers are shown in when --rotate is set.
+type rotation struct {
+	names []string
+	pos   int
+}
+
+// newRotation builds the rotation from --playlist, or every saver if no
+// playlist was given, starting at opts.Screensaver if it is in the list.
+func newRotation(opts shared.ScreensaverOpts) (*rotation, error) {
+	names := opts.Playlist
+	if len(names) == 0 {
+		names = Names(opts.Savers)
+	}
+	for _, name := range names {
+		if _, ok := opts.Savers[name]; !ok {
+			return nil, fmt.Errorf("no such screensaver '%s' in playlist; run gh screensaver -l to see choices", name)
+		}
+	}
+
+	r := &rotation{names: append([]string{}, names...)}
+	if opts.Shuffle {
+		rand.New(rand.NewSource(opts.Seed)).Shuffle(len(r.names), func(i, j int) {
+			r.names[i], r.names[j] = r.names[j], r.names[i]
+		})
+	}
+	for i, name := range r.names {
+		if name == opts.Screensaver {
+			r.pos = i
+			break
+		}
+	}
+
+	return r, nil
+}
+
+func (r *rotation) Current() string {
+	return r.names[r.pos]
+}
+
+func (r *rotation) Next() string {
+	r.pos = (r.pos + 1) % len(r.names)
+	return r.Current()
+}
+
+func (r *rotation) Prev() string {
+	r.pos = (r.pos + len(r.names) - 1) % len(r.names)
+	return r.Current()
+}
+
+// argsFor picks out the saver arguments meant for the saver called name.
+// Arguments can be scoped to one saver by prefixing them with its name, as in
+// --marquee.message=hi; unscoped arguments go to every saver. Arguments
+// scoped to a saver also go to its presets.
+func argsFor(name string, args []string, savers map[string]shared.RegisteredSaver) []string {
+	out := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag := strings.TrimLeft(arg, "-")
+		dot := strings.Index(flag, ".")
+		eq := strings.Index(flag, "=")
+		if !strings.HasPrefix(arg, "-") || dot < 0 || (eq >= 0 && eq < dot) {
+			out = append(out, arg)
+			continue
+		}
+		scope := flag[:dot]
+		if _, ok := savers[scope]; !ok {
+			out = append(out, arg)
+			continue
+		}
+
+		// --saver.input value: the value belongs to the same saver.
+		var value []string
+		if eq < 0 && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			value = []string{args[i+1]}
+			i++
+		}
+		if scope != name && scope != baseName(name) {
+			continue
+		}
+		out = append(out, "--"+flag[dot+1:])
+		out = append(out, value...)
+	}
+	return out
+}
+
+// baseName is the saver a saver:preset name runs.
+func baseName(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
@@ -0,0 +1,709 @@
+// Package screensaver runs terminal screensavers. It is the engine behind gh
+// screensaver, and can be embedded in other programs that want to add savers
+// of their own:
+//
+//	screensaver.Register("clock", NewClockSaver, shared.SaverMetadata{
+//		Description: "Show the time.",
+//	})
+//	err := screensaver.Run(ctx, shared.ScreensaverOpts{Screensaver: "clock"})
+package screensaver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]shared.RegisteredSaver{
+		"marquee":   {Create: savers.NewMarqueeSaver, Metadata: savers.MarqueeMetadata},
+		"fireworks": {Create: savers.NewFireworksSaver, Metadata: savers.FireworksMetadata},
+		"pipes":     {Create: savers.NewPipesSaver, Metadata: savers.PipesMetadata},
+		"starfield": {Create: savers.NewStarfieldSaver, Metadata: savers.StarfieldMetadata},
+		"pollock":   {Create: savers.NewPollockSaver, Metadata: savers.PollockMetadata},
+		"life":      {Create: savers.NewLifeSaver, Metadata: savers.LifeMetadata},
+		// TODO aquarium
+		// TODO noise
+		// TODO issues/pr float by?
+	}
+)
+
+// Register makes a saver available under name to every later call of Savers,
+// and so to Run. Like database/sql.Register, it panics if creator is nil or
+// name is already taken, including by one of the built in savers.
+func Register(name string, creator shared.SaverCreator, metadata shared.SaverMetadata) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if creator == nil {
+		panic("screensaver: Register creator is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("screensaver: Register called twice for saver " + name)
+	}
+	registry[name] = shared.RegisteredSaver{Create: creator, Metadata: metadata}
+}
+
+// Savers returns a copy of every registered saver, by name.
+func Savers() map[string]shared.RegisteredSaver {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	out := map[string]shared.RegisteredSaver{}
+	for name, rs := range registry {
+		out[name] = rs
+	}
+	return out
+}
+
+// Names returns the names in savers, sorted.
+func Names(savers map[string]shared.RegisteredSaver) []string {
+	keys := []string{}
+	for k := range savers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// pickRandom picks one of the savers that fit a width x height screen, with
+// opts.Seed. A saver whose metadata fits but whose inputs make it bigger, as
+// a long banner can, is passed over. If the size isn't known (0x0) or none
+// fit, it picks from all of them.
+func pickRandom(opts shared.ScreensaverOpts, width, height int) string {
+	keys := []string{}
+	for _, name := range Names(opts.Savers) {
+		meta := opts.Savers[name].Metadata
+		if width == 0 || (width >= meta.MinWidth && height >= meta.MinHeight) {
+			keys = append(keys, name)
+		}
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+	if len(keys) == 0 {
+		keys = Names(opts.Savers)
+	} else if width > 0 {
+		for _, ix := range r.Perm(len(keys)) {
+			if fitsWithInputs(opts, keys[ix], width, height) {
+				return keys[ix]
+			}
+		}
+	}
+	return keys[r.Intn(len(keys))]
+}
+
+// fitsWithInputs reports whether the saver name, given its inputs, fits a
+// width x height screen. Only a MinSizer can say, and only once it is
+// created, so the saver is created and closed again. A saver that fails to
+// be created is said to fit, so that the failure is reported when it is
+// created for real.
+func fitsWithInputs(opts shared.ScreensaverOpts, name string, width, height int) (fits bool) {
+	defer func() {
+		if recover() != nil {
+			fits = true
+		}
+	}()
+	registered := opts.Savers[name]
+	opts.Screensaver = name
+	opts.Canvas = shared.NewCanvas(width, height)
+	saver, err := newSaver(registered.Create, opts)
+	if err != nil {
+		return true
+	}
+	defer closeSaver(saver)
+	minW, minH := minSize(saver, registered.Metadata)
+	return width >= minW && height >= minH
+}
+
+// Run runs a saver until ctx is cancelled or, in a terminal, a key is
+// pressed; neither is an error. With opts.Headless set it instead renders
+// opts.Frames frames to opts.Out, and returns ctx.Err() if cancelled before
+// it is done.
+//
+// Zero values in opts are filled in: Savers defaults to Savers(), an empty
+// Screensaver picks one at random using Seed, and Transition, Size and Format
+// default to none, 80x24 and text.
+func Run(ctx context.Context, opts shared.ScreensaverOpts) error {
+	if err := prepare(&opts); err != nil {
+		return err
+	}
+	if opts.Mosaic != "" {
+		if err := useMosaic(&opts); err != nil {
+			return err
+		}
+	}
+	if len(opts.Layers) > 0 {
+		if err := useLayers(&opts); err != nil {
+			return err
+		}
+	}
+	if opts.Headless {
+		return runHeadless(ctx, opts, opts.Out)
+	}
+	return run(ctx, opts)
+}
+
+// prepare fills in defaults and checks that opts make sense.
+func prepare(opts *shared.ScreensaverOpts) error {
+	if opts.Savers == nil {
+		opts.Savers = Savers()
+	}
+	if len(opts.Savers) == 0 {
+		return errors.New("no screensavers are registered")
+	}
+	if opts.Transition == "" {
+		opts.Transition = "none"
+	}
+	if opts.Size == "" {
+		opts.Size = "80x24"
+	}
+	if opts.Format == "" {
+		opts.Format = "text"
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	if opts.Exit == "" {
+		opts.Exit = "key"
+	}
+	if _, err := newExitPolicy(opts.Exit, opts.ExitChord, opts.Controls); err != nil {
+		return err
+	}
+	if opts.Duration < 0 {
+		return fmt.Errorf("--duration must be a positive duration, got %s", opts.Duration)
+	}
+	if opts.Headless && (opts.Rotate > 0 || opts.Lock || opts.Controls) {
+		return errors.New("--headless can't be used with --rotate, --lock or --controls, which need a terminal")
+	}
+	if opts.Lock {
+		if opts.Controls {
+			return errors.New("--controls can't be used with --lock, since keys go to the passphrase prompt")
+		}
+		if opts.Duration > 0 {
+			return errors.New("--duration can't be used with --lock, since it would unlock on its own")
+		}
+		if err := checkLockHash(opts.LockHash); err != nil {
+			return err
+		}
+	}
+
+	if opts.FPS < 0 {
+		return fmt.Errorf("--fps must be a positive number, got %d", opts.FPS)
+	}
+	if opts.Rotate < 0 {
+		return fmt.Errorf("--rotate must be a positive duration, got %s", opts.Rotate)
+	}
+	if opts.Mosaic != "" && (opts.Rotate > 0 || opts.Controls) {
+		return errors.New("--mosaic can't be used with --rotate or --controls")
+	}
+	if len(opts.Layers) > 0 && (opts.Mosaic != "" || opts.Rotate > 0 || opts.Controls) {
+		return errors.New("--layers can't be used with --mosaic, --rotate or --controls")
+	}
+	if opts.Rotate == 0 && !opts.Controls && (len(opts.Playlist) > 0 || opts.Shuffle) {
+		return errors.New("--playlist and --shuffle only make sense with --rotate or --controls")
+	}
+	return validTransition(opts.Transition)
+}
+
+// maxDelta caps how much time a single frame may simulate so that a process
+// that was suspended doesn't wake up and try to catch up on hours of animation.
+const maxDelta = 250 * time.Millisecond
+
+// run shows the saver in the terminal.
+func run(ctx context.Context, opts shared.ScreensaverOpts) (err error) {
+	style := tcell.StyleDefault
+	opts.Style = style
+	if opts.Screensaver == "" {
+		width, height := terminalSize()
+		opts.Screensaver = pickRandom(opts, width, height)
+	}
+
+	// The rotation is also what the next and previous controls move
+	// through, so it is needed whenever those might be used.
+	var rot *rotation
+	if opts.Rotate > 0 || opts.Controls {
+		var err error
+		rot, err = newRotation(opts)
+		if err != nil {
+			return err
+		}
+		opts.Screensaver = rot.Current()
+	}
+
+	registered, ok := opts.Savers[opts.Screensaver]
+	if !ok {
+		return fmt.Errorf("no such screensaver '%s'; run gh screensaver -l to see choices", opts.Screensaver)
+	}
+
+	// Arguments are checked for the savers that were asked for. Any others
+	// the controls reach just ignore arguments they don't take.
+	names := []string{opts.Screensaver}
+	if opts.Rotate > 0 || len(opts.Playlist) > 0 {
+		names = rot.names
+	}
+	if opts.Mosaic != "" || len(opts.Layers) > 0 {
+		// useComposite has checked the arguments of the savers it runs.
+		names = nil
+	}
+	if err := checkSaverArgs(opts, names); err != nil {
+		return err
+	}
+	checked := map[string]bool{}
+	for _, name := range names {
+		checked[name] = true
+	}
+
+	exit, err := newExitPolicy(opts.Exit, opts.ExitChord, opts.Controls)
+	if err != nil {
+		return err
+	}
+	var lk *lock
+	if opts.Lock {
+		lk = newLock(opts.LockHash)
+		exit = exitPolicy{kind: "lock"}
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err = screen.Init(); err != nil {
+		return err
+	}
+	screen.SetStyle(style)
+	// The mouse is only reported when something uses it, since reporting it
+	// stops the terminal from selecting text.
+	useMouse := func(saver shared.Screensaver) {
+		_, handler := saver.(shared.EventHandler)
+		if opts.Controls || exit.wantsMouse() || handler {
+			screen.EnableMouse()
+		} else {
+			screen.DisableMouse()
+		}
+	}
+
+	var (
+		saver shared.Screensaver
+		trans *transition
+		rec   *recorder
+		// What is running, for reporting a crash. While a transition is
+		// updated, the saver running is the outgoing one instead.
+		current      = opts.Screensaver
+		currentSeed  = opts.Seed
+		outgoing     string
+		outgoingSeed int64
+		inTransition bool
+		frame        int
+		// notes are what --debug has to say once the terminal is back.
+		notes []string
+	)
+	// A saver that panics mustn't leave the terminal in raw mode on the
+	// alternate screen, so put it back before saying what happened.
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		width, height := screen.Size()
+		screen.Fini()
+		closeSaver(saver)
+		if trans != nil {
+			closeSaver(trans.from)
+		}
+		if rec != nil {
+			rec.Close()
+		}
+		name, seed := current, currentSeed
+		if inTransition {
+			name, seed = outgoing, outgoingSeed
+		}
+		err = crashed(r, opts, name, frame, width, height, seed)
+	}()
+
+	// Savers draw off screen and the result is copied to the terminal each
+	// frame, which lets transitions mix two savers' frames together.
+	width, height := screen.Size()
+	buf := shared.NewCanvas(atLeast(registered.Metadata, width, height))
+	opts.Canvas = buf
+
+	saver, err = newSaver(registered.Create, opts)
+	if err != nil {
+		screen.Fini()
+		return err
+	}
+	useMouse(saver)
+
+	if opts.Record != "" {
+		width, height := screen.Size()
+		rec, err = newRecorder(opts.Record, width, height)
+		if err != nil {
+			screen.Fini()
+			closeSaver(saver)
+			return err
+		}
+	}
+
+	quit := make(chan struct{})
+	resized := make(chan struct{}, 1)
+	// Input goes to the render loop rather than straight to the saver, so
+	// that savers never see an event in the middle of drawing a frame.
+	events := make(chan tcell.Event, 64)
+	go func() {
+		for {
+			ev := screen.PollEvent()
+			switch ev := ev.(type) {
+			case nil:
+				// The screen has been finalized.
+				return
+			case *tcell.EventKey, *tcell.EventMouse:
+				if exit.quitsOn(ev) {
+					close(quit)
+					return
+				}
+				// Drop input rather than block if the render loop is stuck.
+				select {
+				case events <- ev:
+				default:
+				}
+			case *tcell.EventResize:
+				screen.Sync()
+				// The render loop only needs to know that the size changed, not
+				// how many times, so don't block if it hasn't caught up yet.
+				select {
+				case resized <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	var saverErr error
+	start := time.Now()
+	next := start
+	last := start
+	switches := 0
+	switchAt := start.Add(opts.Rotate)
+	transitionRand := rand.New(rand.NewSource(opts.Seed))
+	paused, showHelp, speed := false, false, 1.0
+	var timeUp <-chan time.Time
+	if opts.Duration > 0 {
+		timeUp = time.After(opts.Duration)
+	}
+
+	// switchTo replaces the running saver with a new one. Each saver gets its
+	// own seed derived from --seed so that the whole session can still be
+	// reproduced.
+	switchTo := func(name string, animate bool) error {
+		switches++
+		sopts := opts
+		sopts.Screensaver = name
+		sopts.Seed = opts.Seed + int64(switches)
+		if opts.Debug {
+			notes = append(notes, boundsNote(current, buf))
+		}
+		prev, prevSeed := current, currentSeed
+		current, currentSeed = name, sopts.Seed
+		sopts.Lenient = opts.Lenient || !checked[name]
+		width, height := screen.Size()
+		nextBuf := shared.NewCanvas(atLeast(opts.Savers[name].Metadata, width, height))
+		sopts.Canvas = nextBuf
+		nextSaver, err := newSaver(opts.Savers[name].Create, sopts)
+		if err != nil {
+			return err
+		}
+		if trans != nil {
+			closeSaver(trans.from)
+			trans = nil
+		}
+		if animate && opts.Transition != "none" {
+			trans = newTransition(opts.Transition, opts.TransitionDuration, saver, buf, transitionRand)
+			outgoing, outgoingSeed = prev, prevSeed
+		} else {
+			closeSaver(saver)
+		}
+		saver, buf = nextSaver, nextBuf
+		useMouse(saver)
+		return nil
+	}
+
+	// With --lock, a saver that fails mustn't end the session before the
+	// passphrase is typed. guard turns its panics into errors, and fallBack
+	// puts a blank screen in its place; the first failure is returned once
+	// the session is unlocked.
+	var lockErr error
+	guard := func(f func() error) (err error) {
+		if lk != nil {
+			defer func() {
+				if r := recover(); r != nil {
+					name, seed := current, currentSeed
+					if inTransition {
+						name, seed = outgoing, outgoingSeed
+						inTransition = false
+					}
+					width, height := screen.Size()
+					err = crashed(r, opts, name, frame, width, height, seed)
+				}
+			}()
+		}
+		return f()
+	}
+	fallBack := func(err error) {
+		if lockErr == nil {
+			lockErr = err
+		}
+		closeSaver(saver)
+		if trans != nil {
+			closeSaver(trans.from)
+			trans = nil
+		}
+		buf.Clear()
+		saver = blank{}
+		useMouse(saver)
+	}
+
+loop:
+	for {
+		// Schedule against when the last frame was due rather than sleeping a
+		// fixed amount after it, so slow frames don't drag the rate down. If we
+		// have fallen more than a frame behind, start over from now instead of
+		// rendering a burst of frames to catch up.
+		next = next.Add(frameInterval(saver, opts.FPS))
+		wait := time.Until(next)
+		if wait < 0 {
+			next = time.Now()
+			wait = 0
+		}
+
+		select {
+		case <-quit:
+			break loop
+		case <-ctx.Done():
+			break loop
+		case <-timeUp:
+			break loop
+		case <-time.After(wait):
+		}
+
+		frame++
+		now := time.Now()
+		delta := now.Sub(last)
+		if delta > maxDelta {
+			delta = maxDelta
+		}
+		last = now
+
+		var switchErr error
+	drain:
+		for {
+			select {
+			case ev := <-events:
+				kev, isKey := ev.(*tcell.EventKey)
+				if lk != nil {
+					if isKey && lk.handle(kev, now) {
+						break loop
+					}
+					continue
+				}
+				// Keys go to the saver before the controls.
+				if h, ok := saver.(shared.EventHandler); ok && h.HandleEvent(ev) {
+					continue
+				}
+				if !isKey || !opts.Controls {
+					continue
+				}
+				switch controlFor(kev) {
+				case ctlPause:
+					paused = !paused
+				case ctlHelp:
+					showHelp = !showHelp
+				case ctlFaster:
+					if speed < maxSpeed {
+						speed *= 2
+					}
+				case ctlSlower:
+					if speed > minSpeed {
+						speed /= 2
+					}
+				case ctlNext:
+					switchErr = switchTo(rot.Next(), true)
+					switchAt = now.Add(opts.Rotate)
+				case ctlPrev:
+					switchErr = switchTo(rot.Prev(), true)
+					switchAt = now.Add(opts.Rotate)
+				case ctlReseed:
+					switchErr = switchTo(rot.Current(), false)
+				}
+			default:
+				break drain
+			}
+		}
+		if switchErr != nil {
+			saverErr = switchErr
+			break loop
+		}
+
+		if opts.Rotate > 0 && !paused && !now.Before(switchAt) {
+			err := guard(func() error { return switchTo(rot.Next(), true) })
+			if err != nil && lk == nil {
+				saverErr = err
+				break loop
+			}
+			if err != nil {
+				fallBack(err)
+			}
+			switchAt = now.Add(opts.Rotate)
+		}
+
+		select {
+		case <-resized:
+			// The new size may be too small for the saver on its way out, so
+			// rather than resize it the transition ends early.
+			if trans != nil {
+				closeSaver(trans.from)
+				trans = nil
+			}
+		default:
+		}
+
+		var (
+			fits       bool
+			minW, minH int
+		)
+		err := guard(func() error {
+			// The saver is paused while the terminal is smaller than it
+			// needs, and keeps the last size that was big enough.
+			width, height := screen.Size()
+			fits, minW, minH = fitSaver(saver, opts.Savers[current].Metadata, buf, width, height)
+
+			if paused {
+				// Hold the rotation where it is too.
+				switchAt = switchAt.Add(delta)
+				return nil
+			}
+			if !fits {
+				return nil
+			}
+			saver.Clear()
+			if err := saver.Update(time.Duration(float64(delta) * speed)); err != nil {
+				return err
+			}
+			if trans != nil {
+				inTransition = true
+				err := trans.Update(delta)
+				inTransition = false
+				if err != nil {
+					return err
+				}
+				if trans.Done() {
+					closeSaver(trans.from)
+					trans = nil
+				}
+			}
+			return nil
+		})
+		if err != nil && lk == nil {
+			saverErr = err
+			break loop
+		}
+		if err != nil {
+			fallBack(err)
+			fits = true
+		}
+		switch {
+		case !fits:
+			screen.Clear()
+			drawTooSmall(screen, "terminal", minW, minH)
+		case trans != nil:
+			trans.Draw(screen, buf)
+		default:
+			blit(screen, buf)
+		}
+		if showHelp {
+			drawHelp(screen, controlStatus(rot.Current(), paused, speed))
+		}
+		if lk != nil {
+			lk.draw(screen, now)
+		}
+		screen.Show()
+		if rec != nil {
+			rec.Frame(screen, time.Since(start))
+		}
+	}
+
+	screen.Fini()
+	closeSaver(saver)
+	if trans != nil {
+		closeSaver(trans.from)
+	}
+	if opts.Debug {
+		notes = append(notes, boundsNote(current, buf))
+		for _, note := range notes {
+			fmt.Fprintln(os.Stderr, note)
+		}
+	}
+
+	if rec != nil {
+		if err := rec.Close(); err != nil && saverErr == nil {
+			saverErr = err
+		}
+	}
+	if saverErr == nil {
+		saverErr = lockErr
+	}
+
+	return saverErr
+}
+
+// newSaver creates a saver on opts.Canvas and configures it from
+// opts.SaverArgs.
+func newSaver(saverInit shared.SaverCreator, opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+	opts.Rand = rand.New(rand.NewSource(opts.Seed))
+
+	saver, err := saverInit(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parseSaverArgs(opts.Screensaver, saver.Inputs(), opts)
+	if err != nil {
+		closeSaver(saver)
+		return nil, err
+	}
+
+	err = saver.SetInputs(values)
+	if err != nil {
+		closeSaver(saver)
+		return nil, err
+	}
+
+	return saver, nil
+}
+
+// closeSaver lets go of anything a saver holds outside the process, such as
+// a plugin's child process.
+func closeSaver(saver shared.Screensaver) {
+	if c, ok := saver.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// frameInterval returns how long to wait between frames: the saver's own
+// preference unless the user asked for a specific frame rate.
+func frameInterval(saver shared.Screensaver, fps int) time.Duration {
+	if fps > 0 {
+		return time.Second / time.Duration(fps)
+	}
+	if interval := saver.Interval(); interval > 0 {
+		return interval
+	}
+	return shared.DefaultInterval
+}
@@ -0,0 +1,212 @@
+package screensaver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// dotSaver draws a single dot that moves one cell right every frame.
+type dotSaver struct {
+	canvas *shared.Canvas
+	x      int
+}
+
+func newDotSaver(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+	d := &dotSaver{}
+	return d, d.Initialize(opts)
+}
+
+func (d *dotSaver) Initialize(opts shared.ScreensaverOpts) error {
+	d.canvas = opts.Canvas
+	return nil
+}
+
+func (d *dotSaver) SetInputs(shared.InputValues) error   { return nil }
+func (d *dotSaver) Inputs() map[string]shared.SaverInput { return nil }
+func (d *dotSaver) Clear()                               { d.canvas.Clear() }
+func (d *dotSaver) Interval() time.Duration              { return shared.DefaultInterval }
+func (d *dotSaver) Resize(width, height int)             {}
+func (d *dotSaver) Update(delta time.Duration) error {
+	d.canvas.SetContent(d.x, 0, '.', nil, tcell.StyleDefault)
+	d.x++
+	return nil
+}
+
+func TestRegisterAndRun(t *testing.T) {
+	Register("test-dot", newDotSaver, shared.SaverMetadata{Description: "A dot."})
+	t.Cleanup(func() { unregister("test-dot") })
+	if _, ok := Savers()["test-dot"]; !ok {
+		t.Fatal("registered saver is missing from Savers")
+	}
+
+	var out bytes.Buffer
+	err := Run(context.Background(), shared.ScreensaverOpts{
+		Screensaver: "test-dot",
+		Headless:    true,
+		Frames:      3,
+		Size:        "5x1",
+		Out:         &out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "  .\n" {
+		t.Errorf("got %q, want the dot in the third column", got)
+	}
+}
+
+// unregister undoes Register, so that a test can run more than once.
+func unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "pipes") {
+			t.Errorf("got %v, want a panic about pipes", r)
+		}
+	}()
+	Register("pipes", newDotSaver, shared.SaverMetadata{})
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Run(ctx, shared.ScreensaverOpts{
+		Screensaver: "pipes",
+		Headless:    true,
+		Frames:      10,
+		Out:         &bytes.Buffer{},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+// crashSaver is a dotSaver that panics once the dot reaches the third column.
+type crashSaver struct {
+	dotSaver
+}
+
+func (c *crashSaver) Update(delta time.Duration) error {
+	if c.x == 2 {
+		panic("off the end")
+	}
+	return c.dotSaver.Update(delta)
+}
+
+func TestRunRecoversPanic(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "crash.txt")
+	err := Run(context.Background(), shared.ScreensaverOpts{
+		Screensaver: "crash",
+		Savers: map[string]shared.RegisteredSaver{
+			"crash": {Create: func(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+				c := &crashSaver{}
+				return c, c.Initialize(opts)
+			}},
+		},
+		Headless:    true,
+		Frames:      5,
+		Size:        "5x1",
+		Seed:        42,
+		Out:         &bytes.Buffer{},
+		CrashReport: report,
+	})
+
+	var p *SaverPanic
+	if !errors.As(err, &p) {
+		t.Fatalf("got %v, want a SaverPanic", err)
+	}
+	if p.Saver != "crash" || p.Frame != 3 || p.Width != 5 || p.Height != 1 || p.Seed != 42 {
+		t.Errorf("got %+v, want crash on frame 3 at 5x1 with seed 42", p)
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "panic: off the end") {
+		t.Errorf("crash report doesn't say what happened:\n%s", data)
+	}
+}
+
+// tallSaver is a dotSaver that, once created, says it needs 40 rows.
+type tallSaver struct {
+	dotSaver
+}
+
+func (t *tallSaver) MinSize() (int, int) { return 1, 40 }
+
+func TestPickRandomFits(t *testing.T) {
+	savers := map[string]shared.RegisteredSaver{
+		"big":   {Create: newDotSaver, Metadata: shared.SaverMetadata{MinWidth: 100, MinHeight: 40}},
+		"small": {Create: newDotSaver, Metadata: shared.SaverMetadata{MinWidth: 10, MinHeight: 5}},
+		"tall": {Create: func(opts shared.ScreensaverOpts) (shared.Screensaver, error) {
+			s := &tallSaver{}
+			return s, s.Initialize(opts)
+		}},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		opts := shared.ScreensaverOpts{Savers: savers, Seed: seed}
+		if got := pickRandom(opts, 80, 24); got != "small" {
+			t.Fatalf("seed %d picked %s, which doesn't fit 80x24", seed, got)
+		}
+	}
+	// With nothing that fits, anything goes.
+	if got := pickRandom(shared.ScreensaverOpts{Savers: savers, Seed: 1}, 5, 4); got == "" {
+		t.Error("picked nothing for a tiny screen")
+	}
+}
+
+func TestRunTooSmall(t *testing.T) {
+	err := Run(context.Background(), shared.ScreensaverOpts{
+		Screensaver: "fireworks",
+		Headless:    true,
+		Frames:      1,
+		Size:        "10x10",
+		Out:         &bytes.Buffer{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "at least 12x12") {
+		t.Errorf("got %v, want an error saying fireworks needs 12x12", err)
+	}
+}
+
+func TestRunTooShort(t *testing.T) {
+	err := Run(context.Background(), shared.ScreensaverOpts{
+		Screensaver: "fireworks",
+		Headless:    true,
+		Frames:      1,
+		Size:        "40x10",
+		Out:         &bytes.Buffer{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "at least 12 rows, not 10") {
+		t.Errorf("got %v, want an error saying fireworks needs 12 rows", err)
+	}
+}
+
+func TestHeadlessRejectsTerminalOptions(t *testing.T) {
+	for _, opts := range []shared.ScreensaverOpts{
+		{Rotate: time.Minute},
+		{Lock: true},
+		{Controls: true},
+	} {
+		opts.Screensaver = "fireworks"
+		opts.Headless = true
+		opts.Out = &bytes.Buffer{}
+		err := Run(context.Background(), opts)
+		if err == nil || !strings.Contains(err.Error(), "--headless can't be used") {
+			t.Errorf("rotate %s, lock %v, controls %v: got %v, want --headless to be refused",
+				opts.Rotate, opts.Lock, opts.Controls, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package screensaver
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+	"golang.org/x/term"
+)
+
+// terminalSize is the size of the terminal on stdout, or 0x0 if there isn't
+// one.
+func terminalSize() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0, 0
+	}
+	return width, height
+}
+
+// minSize is the smallest screen a saver can run on: whatever it says now
+// that its inputs are set, if it says, or else what its metadata says.
+func minSize(saver shared.Screensaver, meta shared.SaverMetadata) (int, int) {
+	if ms, ok := saver.(shared.MinSizer); ok {
+		return ms.MinSize()
+	}
+	return meta.MinWidth, meta.MinHeight
+}
+
+// atLeast grows width and height to the given minimum, so that a saver is
+// never created on a screen smaller than its metadata allows.
+func atLeast(meta shared.SaverMetadata, width, height int) (int, int) {
+	if width < meta.MinWidth {
+		width = meta.MinWidth
+	}
+	if height < meta.MinHeight {
+		height = meta.MinHeight
+	}
+	return width, height
+}
+
+// shortfall says how big a width x height screen needs to be to reach minW x
+// minH, and how big it is, in terms of whichever dimension falls short: rows,
+// columns or both.
+func shortfall(minW, minH, width, height int) (need, have string) {
+	switch {
+	case width >= minW:
+		return fmt.Sprintf("%d rows", minH), strconv.Itoa(height)
+	case height >= minH:
+		return fmt.Sprintf("%d columns", minW), strconv.Itoa(width)
+	}
+	return fmt.Sprintf("%dx%d", minW, minH), fmt.Sprintf("%dx%d", width, height)
+}
+
+// tooSmallError explains that a headless screen is too small for a saver.
+func tooSmallError(name string, minW, minH, width, height int) error {
+	need, have := shortfall(minW, minH, width, height)
+	return fmt.Errorf("%s needs a screen of at least %s, not %s", name, need, have)
+}
+
+// boundsNote says how much a saver drew outside its canvas, for --debug.
+func boundsNote(name string, canvas *shared.Canvas) string {
+	width, height := canvas.Size()
+	return fmt.Sprintf("debug: %s drew %d cells outside its %dx%d canvas", name, canvas.OutOfBounds(), width, height)
+}
+
+// fitSaver gives a saver drawing on canvas the size width x height, if that
+// is at least its minimum, and reports whether it is along with what the
+// minimum is. A saver that doesn't fit keeps the last size that did, and
+// mustn't be updated until it fits again.
+func fitSaver(saver shared.Screensaver, meta shared.SaverMetadata, canvas *shared.Canvas, width, height int) (fits bool, minW, minH int) {
+	minW, minH = minSize(saver, meta)
+	if width < minW || height < minH {
+		return false, minW, minH
+	}
+	if w, h := canvas.Size(); w != width || h != height {
+		canvas.SetSize(width, height)
+		saver.Resize(width, height)
+		// The minimum can depend on the size, as a banner's does.
+		minW, minH = minSize(saver, meta)
+	}
+	return width >= minW && height >= minH, minW, minH
+}
+
+// cellWriter is what drawTooSmall draws on: the terminal, or a canvas.
+type cellWriter interface {
+	Size() (int, int)
+	SetContent(x, y int, mainc rune, combc []rune, style tcell.Style)
+}
+
+// drawTooSmall says in the middle of screen how big it needs to be. what is
+// what the screen is to the user, like the terminal.
+func drawTooSmall(screen cellWriter, what string, minW, minH int) {
+	width, height := screen.Size()
+	need, _ := shortfall(minW, minH, width, height)
+	msg := fmt.Sprintf("%s too small (need %s)", what, need)
+	if runewidth.StringWidth(msg) > width {
+		msg = "need " + need
+	}
+	x := (width - runewidth.StringWidth(msg)) / 2
+	if x < 0 {
+		x = 0
+	}
+	for _, r := range msg {
+		screen.SetContent(x, height/2, r, nil, tcell.StyleDefault)
+		x += runewidth.RuneWidth(r)
+	}
+}
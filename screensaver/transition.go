@@ -0,0 +1,179 @@
+package screensaver
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+// Transitions are the ways Run can switch from one saver to the next.
+var Transitions = []string{"none", "wipe", "dissolve", "slide", "fade"}
+
+func validTransition(kind string) error {
+	for _, k := range Transitions {
+		if k == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown transition '%s'; must be one of %s", kind, strings.Join(Transitions, ", "))
+}
+
+// blit copies every cell of src onto dst.
+func blit(dst tcell.Screen, src *shared.Canvas) {
+	width, height := dst.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			copyCell(dst, x, y, src, x, y)
+		}
+	}
+}
+
+// copyCell copies a cell of src onto dst. Cells that nothing was drawn in
+// are blank.
+func copyCell(dst tcell.Screen, x, y int, src *shared.Canvas, sx, sy int) {
+	cell := src.Cell(sx, sy)
+	if !cell.Set() {
+		dst.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		return
+	}
+	dst.SetContent(x, y, cell.Rune, cell.Comb, cell.Style)
+}
+
+// transition blends the last frames of an outgoing saver with the first
+// frames of the one replacing it. Both keep animating until it is done.
+type transition struct {
+	kind     string
+	duration time.Duration
+	elapsed  time.Duration
+
+	from    shared.Screensaver
+	fromBuf *shared.Canvas
+
+	// rank orders the cells for dissolve: cell i is revealed once rank[i] is
+	// below progress times the number of cells.
+	rank []int
+	rand *rand.Rand
+}
+
+func newTransition(kind string, duration time.Duration, from shared.Screensaver, fromBuf *shared.Canvas, r *rand.Rand) *transition {
+	t := &transition{
+		kind:     kind,
+		duration: duration,
+		from:     from,
+		fromBuf:  fromBuf,
+		rand:     r,
+	}
+	if kind == "dissolve" {
+		width, height := fromBuf.Size()
+		t.rank = t.rand.Perm(width * height)
+	}
+	return t
+}
+
+// Update advances the outgoing saver and the transition itself.
+func (t *transition) Update(delta time.Duration) error {
+	t.elapsed += delta
+	t.from.Clear()
+	return t.from.Update(delta)
+}
+
+func (t *transition) Done() bool {
+	return t.elapsed >= t.duration
+}
+
+// Draw composites the outgoing and incoming frames onto dst.
+func (t *transition) Draw(dst tcell.Screen, to *shared.Canvas) {
+	progress := float64(t.elapsed) / float64(t.duration)
+	if progress > 1 {
+		progress = 1
+	}
+	width, height := dst.Size()
+
+	switch t.kind {
+	case "wipe":
+		edge := int(progress * float64(width))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if x < edge {
+					copyCell(dst, x, y, to, x, y)
+				} else {
+					copyCell(dst, x, y, t.fromBuf, x, y)
+				}
+			}
+		}
+	case "dissolve":
+		// The outgoing canvas needn't be the terminal's size, if the terminal
+		// was too small for it when the switch came, so the order the cells
+		// dissolve in is made for the size actually drawn.
+		if len(t.rank) != width*height {
+			t.rank = t.rand.Perm(width * height)
+		}
+		revealed := int(progress * float64(width*height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if t.rank[y*width+x] < revealed {
+					copyCell(dst, x, y, to, x, y)
+				} else {
+					copyCell(dst, x, y, t.fromBuf, x, y)
+				}
+			}
+		}
+	case "slide":
+		// The incoming frame pushes the outgoing one off to the left.
+		offset := int(progress * float64(width))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if sx := x + offset; sx < width {
+					copyCell(dst, x, y, t.fromBuf, sx, y)
+				} else {
+					copyCell(dst, x, y, to, sx-width, y)
+				}
+			}
+		}
+	case "fade":
+		// Fade the outgoing frame to black over the first half, then the
+		// incoming one up from black over the second.
+		src := t.fromBuf
+		level := 1 - 2*progress
+		if progress >= 0.5 {
+			src, level = to, 2*progress-1
+		}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				cell := src.Cell(x, y)
+				if !cell.Set() || level < 0.1 {
+					cell.Rune, cell.Comb = ' ', nil
+				}
+				dst.SetContent(x, y, cell.Rune, cell.Comb, dim(cell.Style, level))
+			}
+		}
+	default:
+		blit(dst, to)
+	}
+}
+
+// dim scales a style's colors towards black. The terminal's default
+// foreground is assumed to be light and its default background dark.
+func dim(style tcell.Style, level float64) tcell.Style {
+	fg, bg, _ := style.Decompose()
+	if !fg.Valid() {
+		fg = tcell.ColorSilver
+	}
+	style = style.Foreground(scaleColor(fg, level))
+	if bg.Valid() {
+		style = style.Background(scaleColor(bg, level))
+	}
+	return style
+}
+
+func scaleColor(c tcell.Color, level float64) tcell.Color {
+	r, g, b := c.RGB()
+	return tcell.NewRGBColor(
+		int32(float64(r)*level),
+		int32(float64(g)*level),
+		int32(float64(b)*level))
+}
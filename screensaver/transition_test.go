@@ -0,0 +1,35 @@
+package screensaver
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/vilmibm/gh-screensaver/savers/shared"
+)
+
+func TestTransitionDrawsAnySize(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(200, 5)
+
+	// An outgoing saver paused as too small keeps its last canvas, which
+	// can be neither as wide nor as short as the terminal.
+	for _, kind := range Transitions {
+		fromBuf := shared.NewCanvas(20, 10)
+		fromBuf.SetContent(0, 0, 'f', nil, tcell.StyleDefault)
+		to := shared.NewCanvas(200, 5)
+		to.SetContent(0, 0, 't', nil, tcell.StyleDefault)
+		from, _ := newDotSaver(shared.ScreensaverOpts{Canvas: fromBuf})
+
+		tr := newTransition(kind, time.Second, from, fromBuf, rand.New(rand.NewSource(1)))
+		if err := tr.Update(time.Second / 2); err != nil {
+			t.Fatal(err)
+		}
+		tr.Draw(screen, to)
+	}
+}